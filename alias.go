@@ -0,0 +1,147 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+
+	"go.uber.org/dig/internal/dot"
+)
+
+// Names is a ProvideOption that specifies that all values produced by a
+// constructor should be available under each of the given names. The values
+// are provided under the first name and every following name is an alias for
+// it: the constructor is called at most once and all names share the same
+// value.
+//
+//   c.Provide(NewConnection, dig.Names("db", "database"))
+//
+// An empty first name provides the values without a name, leaving them
+// available to unnamed consumers as well.
+//
+//   c.Provide(NewConnection, dig.Names("", "legacy-db"))
+//
+// This option cannot be provided for constructors which produce value groups.
+// Aliases apply only to values that are not part of a result object.
+func Names(names ...string) ProvideOption {
+	return provideOptionFunc(func(opts *provideOptions) {
+		if len(names) == 0 {
+			return
+		}
+		opts.Name = names[0]
+		opts.Aliases = append(opts.Aliases, names[1:]...)
+	})
+}
+
+// Alias makes the value of type T provided under the given name available
+// under alias as well, where t is a pointer to T. The constructor of the
+// value is not called again when the alias is requested.
+//
+//   c.Provide(NewConnection, dig.Name("db"))
+//   c.Alias("database", "db", new(*sql.DB))
+//
+// The aliased value must already be provided to the container, and alias must
+// not already be provided or aliased.
+func (c *Container) Alias(alias, name string, t interface{}) error {
+	pt := reflect.TypeOf(t)
+	if pt == nil {
+		return errors.New("can't alias an untyped nil")
+	}
+	if pt.Kind() != reflect.Ptr {
+		return errf("must provide a pointer to the aliased type, got %v (type %v)", t, pt)
+	}
+
+	target := key{name: name, t: pt.Elem()}
+	a := key{name: alias, t: pt.Elem()}
+	if err := c.addAlias(a, target); err != nil {
+		return errf("cannot alias %v as %v", target, a, err)
+	}
+	return nil
+}
+
+func (c *Container) addAlias(alias, target key) error {
+	if err := validateAliasName(alias.name); err != nil {
+		return err
+	}
+
+	target = c.resolveAlias(target)
+	if len(c.providers[target]) == 0 {
+		return errf("%v is not provided", target)
+	}
+
+	if ps := c.providers[alias]; len(ps) > 0 {
+		cons := make([]string, len(ps))
+		for i, p := range ps {
+			cons[i] = p.Location().String()
+		}
+		return errf("already provided by %v", strings.Join(cons, "; "))
+	}
+	if existing, ok := c.aliases[alias]; ok {
+		return errf("already an alias for %v", existing)
+	}
+
+	c.aliases[alias] = target
+	return nil
+}
+
+// resolveAlias returns the key that k is an alias for. Keys which are not
+// aliases are returned as-is.
+func (c *Container) resolveAlias(k key) key {
+	if target, ok := c.aliases[k]; ok {
+		return target
+	}
+	return k
+}
+
+func validateAliasName(name string) error {
+	if name == "" {
+		return errors.New("invalid alias: aliases must not be empty")
+	}
+	// Aliases are names and must be representable inside a backquoted
+	// string, see provideOptions.Validate.
+	if strings.ContainsRune(name, '`') {
+		return errf("invalid alias %q: names cannot contain backquotes", name)
+	}
+	return nil
+}
+
+// addDotAliases adds the aliases of the container to the DOT graph in a
+// deterministic order.
+func (c *Container) addDotAliases(dg *dot.Graph) {
+	aliases := make([]key, 0, len(c.aliases))
+	for a := range c.aliases {
+		aliases = append(aliases, a)
+	}
+	sort.Slice(aliases, func(i, j int) bool {
+		return aliases[i].String() < aliases[j].String()
+	})
+
+	for _, a := range aliases {
+		target := c.aliases[a]
+		dg.AddAlias(&dot.Alias{
+			Node:   &dot.Node{Type: a.t, Name: a.name},
+			Target: &dot.Node{Type: target.t, Name: target.name},
+		})
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAliases(t *testing.T) {
+	type A struct{ idx int }
+
+	t.Run("Names option shares a single value", func(t *testing.T) {
+		c := New()
+
+		calls := 0
+		require.NoError(t, c.Provide(func() *A {
+			calls++
+			return &A{idx: calls}
+		}, Names("new", "old", "older")))
+
+		type param struct {
+			In
+
+			New   *A `name:"new"`
+			Old   *A `name:"old"`
+			Older *A `name:"older"`
+		}
+		require.NoError(t, c.Invoke(func(p param) {
+			assert.Equal(t, 1, p.New.idx)
+			assert.True(t, p.New == p.Old, "alias must refer to the same value")
+			assert.True(t, p.New == p.Older, "alias must refer to the same value")
+		}))
+		assert.Equal(t, 1, calls, "constructor must be called once")
+		assert.Len(t, c.nodes, 1, "aliases must not create new nodes")
+	})
+
+	t.Run("Names with an unnamed primary", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() *A { return &A{idx: 1} }, Names("", "legacy")))
+
+		type param struct {
+			In
+
+			Legacy *A `name:"legacy"`
+		}
+		require.NoError(t, c.Invoke(func(a *A, p param) {
+			assert.True(t, a == p.Legacy)
+		}))
+	})
+
+	t.Run("Names skips result object fields", func(t *testing.T) {
+		type B struct{}
+		type out struct {
+			Out
+
+			First  *A `name:"first"`
+			Second *A `name:"second"`
+		}
+
+		c := New()
+		require.NoError(t, c.Provide(func() (out, *B) {
+			return out{First: &A{idx: 1}, Second: &A{idx: 2}}, &B{}
+		}, Names("", "legacy")))
+
+		type param struct {
+			In
+
+			First  *A `name:"first"`
+			Second *A `name:"second"`
+			Legacy *B `name:"legacy"`
+		}
+		require.NoError(t, c.Invoke(func(b *B, p param) {
+			assert.Equal(t, 1, p.First.idx)
+			assert.Equal(t, 2, p.Second.idx)
+			assert.True(t, b == p.Legacy)
+		}))
+		assert.Len(t, c.aliases, 1, "only the top-level result must be aliased")
+	})
+
+	t.Run("Alias method", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() *A { return &A{idx: 1} }, Name("db")))
+		require.NoError(t, c.Alias("database", "db", new(*A)))
+		require.NoError(t, c.Alias("store", "database", new(*A)), "aliases of aliases must resolve")
+
+		type param struct {
+			In
+
+			DB    *A `name:"db"`
+			Store *A `name:"store"`
+		}
+		require.NoError(t, c.Invoke(func(p param) {
+			assert.True(t, p.DB == p.Store)
+		}))
+		assert.Equal(t, key{name: "db", t: reflect.TypeOf(&A{})}, c.aliases[key{name: "store", t: reflect.TypeOf(&A{})}])
+	})
+
+	t.Run("aliases are visible to inject tags", func(t *testing.T) {
+		c := New()
+		type Bean struct {
+			A *A `inject:"old"`
+		}
+		require.NoError(t, c.Provide(func() *A { return &A{idx: 1} }, Names("new", "old")))
+		require.NoError(t, c.Provide(func() *Bean { return &Bean{} }))
+		require.NoError(t, c.Invoke(func(b *Bean) {
+			require.NotNil(t, b.A)
+			assert.Equal(t, 1, b.A.idx)
+		}))
+	})
+
+	t.Run("cycle through an alias", func(t *testing.T) {
+		c := New()
+		type param struct {
+			In
+
+			A *A `name:"old"`
+		}
+		err := c.Provide(func(param) *A { return &A{} }, Names("new", "old"))
+		require.Error(t, err)
+		assert.True(t, IsCycleDetected(err), "expected a cycle, got %v", err)
+		assert.Empty(t, c.aliases, "aliases must be rolled back")
+	})
+}
+
+func TestAliasErrors(t *testing.T) {
+	type A struct{}
+
+	t.Run("alias conflicts with provided value", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() A { return A{} }, Name("old")))
+
+		err := c.Provide(func() A { return A{} }, Names("new", "old"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `cannot alias dig.A[name="new"] as dig.A[name="old"]`)
+		assert.Contains(t, err.Error(), "already provided by")
+	})
+
+	t.Run("provided value conflicts with alias", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() A { return A{} }, Names("new", "old")))
+
+		err := c.Provide(func() A { return A{} }, Name("old"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `cannot provide dig.A[name="old"]`)
+		assert.Contains(t, err.Error(), `already an alias for dig.A[name="new"]`)
+	})
+
+	t.Run("alias repeats primary name", func(t *testing.T) {
+		c := New()
+		err := c.Provide(func() A { return A{} }, Names("new", "new"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already provided by [0]")
+	})
+
+	t.Run("alias requested twice", func(t *testing.T) {
+		c := New()
+		err := c.Provide(func() A { return A{} }, Names("new", "old", "old"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "alias requested more than once")
+	})
+
+	t.Run("aliases with groups", func(t *testing.T) {
+		c := New()
+		err := c.Provide(func() A { return A{} }, Group("g"), Names("", "old"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot use aliases with value groups")
+	})
+
+	t.Run("invalid alias names", func(t *testing.T) {
+		c := New()
+		err := c.Provide(func() A { return A{} }, Names("new", ""))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "aliases must not be empty")
+
+		err = c.Provide(func() A { return A{} }, Names("new", "o`ld"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "names cannot contain backquotes")
+	})
+
+	t.Run("Alias of missing value", func(t *testing.T) {
+		c := New()
+		err := c.Alias("new", "old", new(A))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `dig.A[name="old"] is not provided`)
+	})
+
+	t.Run("Alias requires a pointer", func(t *testing.T) {
+		c := New()
+		err := c.Alias("new", "old", A{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must provide a pointer to the aliased type")
+
+		err = c.Alias("new", "old", nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "can't alias an untyped nil")
+	})
+
+	t.Run("Alias conflicts", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() A { return A{} }, Name("a")))
+		require.NoError(t, c.Provide(func() A { return A{} }, Name("b")))
+		require.NoError(t, c.Alias("c", "a", new(A)))
+
+		err := c.Alias("b", "a", new(A))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already provided by")
+
+		err = c.Alias("c", "b", new(A))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `already an alias for dig.A[name="a"]`)
+	})
+}
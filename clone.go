@@ -0,0 +1,273 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"time"
+
+	"go.uber.org/dig/internal/digreflect"
+)
+
+// A CloneOption modifies the default behavior of Clone.
+type CloneOption interface {
+	applyCloneOption(*cloneOptions)
+}
+
+type cloneOptions struct {
+	Values bool
+	DryRun *bool
+}
+
+type cloneOptionFunc func(*cloneOptions)
+
+func (f cloneOptionFunc) applyCloneOption(opts *cloneOptions) { f(opts) }
+
+// CloneValues is a CloneOption that copies the values already built by the
+// container into the clone. Constructors which were already called are not
+// called again by the clone and both containers share those values.
+func CloneValues() CloneOption {
+	return cloneOptionFunc(func(opts *cloneOptions) {
+		opts.Values = true
+	})
+}
+
+// CloneDryRun is a CloneOption which, when set to true, disables invocation
+// of functions supplied to Provide and Invoke in the clone, regardless of
+// the DryRun option of the original container. See also DryRun.
+func CloneDryRun(dry bool) CloneOption {
+	return cloneOptionFunc(func(opts *cloneOptions) {
+		opts.DryRun = &dry
+	})
+}
+
+// Clone returns a new Container with the same constructors as this one.
+// Constructors provided to either container afterwards are not visible to the
+// other one.
+//
+// By default, the clone does not contain any of the values that were already
+// built, so every constructor may be called again by the clone. Use the
+// CloneValues option to copy these values as well.
+//
+// This allows building a large container once and cloning it cheaply for each
+// test.
+func (c *Container) Clone(opts ...CloneOption) *Container {
+	var options cloneOptions
+	for _, o := range opts {
+		o.applyCloneOption(&options)
+	}
+
+	clone := &Container{
+		providers:                make(map[key][]*node, len(c.providers)),
+		nodes:                    make([]*node, 0, len(c.nodes)),
+		nodeSeq:                  c.nodeSeq,
+		values:                   make(map[key]reflect.Value),
+		groups:                   make(map[key][]reflect.Value),
+		aliases:                  make(map[key]key, len(c.aliases)),
+		rand:                     rand.New(rand.NewSource(time.Now().UnixNano())),
+		isVerifiedAcyclic:        c.isVerifiedAcyclic,
+		deferAcyclicVerification: c.deferAcyclicVerification,
+		invokerFn:                c.invokerFn,
+		eventHandlers:            c.eventHandlers,
+		hooks:                    c.hooks,
+		recoverPanics:            c.recoverPanics,
+		graph:                    newInjectGraph(),
+		containerExt:             newContainerExt(),
+	}
+	if c.callStack != nil {
+		clone.callStack = new(callStack)
+	}
+
+	if options.DryRun != nil {
+		DryRun(*options.DryRun).applyOption(clone)
+	}
+
+	nodes := make(map[*node]*node, len(c.nodes))
+	for _, n := range c.nodes {
+		cn := *n
+		if !options.Values {
+			cn.setState(nodeState{})
+		}
+		nodes[n] = &cn
+		clone.nodes = append(clone.nodes, &cn)
+	}
+	for k, ps := range c.providers {
+		cps := make([]*node, len(ps))
+		for i, p := range ps {
+			cps[i] = nodes[p]
+		}
+		clone.providers[k] = cps
+	}
+	for a, k := range c.aliases {
+		clone.aliases[a] = k
+	}
+	for k, f := range c.intercepts {
+		clone.intercepts[k] = f
+	}
+	for k, p := range c.passives {
+		clone.passives[k] = p
+	}
+	clone.uuid = c.uuid
+
+	if options.Values {
+		clone.values = copyValues(c.values)
+		clone.groups = copyGroups(c.groups)
+		clone.invoked = append([]invocation(nil), c.invoked...)
+	}
+
+	return clone
+}
+
+// Snapshot records the values built by a Container so that the Container can
+// later be reset to that state with Restore.
+type Snapshot struct {
+	c      *Container
+	values map[key]reflect.Value
+	groups map[key][]reflect.Value
+	nodes  map[*node]nodeState
+
+	invoked []invocation
+}
+
+// nodeState is the part of a node that changes when its constructor is
+// called.
+type nodeState struct {
+	called   bool
+	duration time.Duration
+	consumed int
+	injected bool
+	total    time.Duration
+	callers  []*digreflect.Func
+}
+
+func (n *node) state() nodeState {
+	return nodeState{
+		called:   n.called,
+		duration: n.duration,
+		consumed: n.consumed,
+		injected: n.injected,
+		total:    n.total,
+		callers:  n.callers,
+	}
+}
+
+func (n *node) setState(s nodeState) {
+	n.called = s.called
+	n.duration = s.duration
+	n.consumed = s.consumed
+	n.injected = s.injected
+	n.total = s.total
+	n.callers = s.callers
+}
+
+// Snapshot records the values that were built by the container so far, along
+// with what the container knows about the calls that built them: the
+// runtime information shown by VisualizeRuntime and Profile, and the
+// invoked functions that Explain starts from.
+//
+// Constructors provided to the container after the snapshot was taken are
+// not removed by Restore.
+func (c *Container) Snapshot() *Snapshot {
+	s := &Snapshot{
+		c:      c,
+		values: copyValues(c.values),
+		groups: copyGroups(c.groups),
+		nodes:  make(map[*node]nodeState, len(c.nodes)),
+
+		invoked: append([]invocation(nil), c.invoked...),
+	}
+	for _, n := range c.nodes {
+		s.nodes[n] = n.state()
+	}
+	return s
+}
+
+// Restore resets the container to the state recorded by the given snapshot.
+// Values built since the snapshot was taken are discarded and their
+// constructors will be called again when the values are next requested. The
+// runtime information of the constructors and the functions passed to Invoke
+// since the snapshot are forgotten too.
+//
+// Constructors provided or replaced since the snapshot was taken are kept,
+// and the values they provide are not restored: the recorded values of a
+// constructor replaced with Force are discarded so that the new constructor
+// builds them.
+//
+// The snapshot must have been taken from this container.
+func (c *Container) Restore(s *Snapshot) error {
+	if s == nil {
+		return errors.New("can't restore a nil snapshot")
+	}
+	if s.c != c {
+		return errors.New("can't restore a snapshot of another container")
+	}
+
+	c.values = make(map[key]reflect.Value, len(s.values))
+	for k, v := range s.values {
+		if s.providedBySnapshot(k) {
+			c.values[k] = v
+		}
+	}
+	c.groups = make(map[key][]reflect.Value, len(s.groups))
+	for k, vs := range s.groups {
+		if s.providedBySnapshot(k) {
+			c.groups[k] = append([]reflect.Value(nil), vs...)
+		}
+	}
+	// Nodes provided after the snapshot get their zero state back.
+	for _, n := range c.nodes {
+		n.setState(s.nodes[n])
+	}
+	c.invoked = append([]invocation(nil), s.invoked...)
+
+	// The inject graph may reference values built after the snapshot.
+	c.graph = newInjectGraph()
+	return nil
+}
+
+// providedBySnapshot reports whether all the constructors that currently
+// provide the given key were already provided when the snapshot was taken.
+func (s *Snapshot) providedBySnapshot(k key) bool {
+	for _, n := range s.c.providers[k] {
+		if _, ok := s.nodes[n]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func copyValues(values map[key]reflect.Value) map[key]reflect.Value {
+	cp := make(map[key]reflect.Value, len(values))
+	for k, v := range values {
+		cp[k] = v
+	}
+	return cp
+}
+
+func copyGroups(groups map[key][]reflect.Value) map[key][]reflect.Value {
+	cp := make(map[key][]reflect.Value, len(groups))
+	for k, vs := range groups {
+		cp[k] = append([]reflect.Value(nil), vs...)
+	}
+	return cp
+}
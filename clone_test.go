@@ -0,0 +1,221 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClone(t *testing.T) {
+	type A struct{ idx int }
+	type B struct{}
+
+	t.Run("clone builds values independently", func(t *testing.T) {
+		c := New()
+		calls := 0
+		require.NoError(t, c.Provide(func() *A {
+			calls++
+			return &A{idx: calls}
+		}))
+
+		var orig *A
+		require.NoError(t, c.Invoke(func(a *A) { orig = a }))
+
+		clone := c.Clone()
+		require.NoError(t, clone.Invoke(func(a *A) {
+			assert.Equal(t, 2, a.idx, "clone must call the constructor again")
+			assert.False(t, a == orig)
+		}))
+		require.NoError(t, c.Invoke(func(a *A) {
+			assert.True(t, a == orig, "original must keep its value")
+		}))
+	})
+
+	t.Run("CloneValues copies built values", func(t *testing.T) {
+		c := New()
+		calls := 0
+		require.NoError(t, c.Provide(func() *A {
+			calls++
+			return &A{idx: calls}
+		}))
+		require.NoError(t, c.Invoke(func(*A) {}))
+
+		clone := c.Clone(CloneValues())
+		require.NoError(t, clone.Invoke(func(a *A) {
+			assert.Equal(t, 1, a.idx)
+		}))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("providers are independent", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() *A { return &A{} }))
+
+		clone := c.Clone()
+		require.NoError(t, clone.Provide(func(*A) *B { return &B{} }))
+		require.NoError(t, clone.Invoke(func(*B) {}))
+
+		err := c.Invoke(func(*B) {})
+		require.Error(t, err, "original must not see providers of the clone")
+	})
+
+	t.Run("aliases and passive providers are cloned", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() *A { return &A{idx: 1} }, Names("a", "b")))
+		require.NoError(t, c.PassiveProvide(func(name string) (*DB, error) {
+			return &DB{Name: name}, nil
+		}))
+
+		clone := c.Clone()
+
+		type param struct {
+			In
+
+			A  *A  `name:"b"`
+			DB *DB `name:"main"`
+		}
+		require.NoError(t, clone.Invoke(func(p param) {
+			assert.Equal(t, 1, p.A.idx)
+			assert.Equal(t, "main", p.DB.Name)
+		}))
+		assert.Empty(t, c.getValueProviders("main", reflect.TypeOf(&DB{})),
+			"passive providers must provide into the clone")
+	})
+
+	t.Run("randomness of the original is untouched", func(t *testing.T) {
+		c := New(setRand(rand.New(rand.NewSource(1))))
+		c.Clone()
+		assert.Equal(t, rand.New(rand.NewSource(1)).Int63(), c.rand.Int63())
+	})
+
+	t.Run("CloneDryRun", func(t *testing.T) {
+		c := New()
+		var calls int
+		require.NoError(t, c.Provide(func() *A {
+			calls++
+			return &A{}
+		}))
+
+		dry := c.Clone(CloneDryRun(true))
+		require.NoError(t, dry.Invoke(func(*A) { calls++ }))
+		assert.Equal(t, 0, calls, "dry-run clone must not call functions")
+
+		wet := dry.Clone(CloneDryRun(false))
+		require.NoError(t, wet.Invoke(func(*A) { calls++ }))
+		assert.Equal(t, 2, calls)
+	})
+}
+
+func TestSnapshot(t *testing.T) {
+	type A struct{ idx int }
+	type B struct{ a *A }
+
+	t.Run("restore discards values built after snapshot", func(t *testing.T) {
+		c := New()
+		aCalls, bCalls := 0, 0
+		require.NoError(t, c.Provide(func() *A {
+			aCalls++
+			return &A{idx: aCalls}
+		}))
+		require.NoError(t, c.Provide(func(a *A) *B {
+			bCalls++
+			return &B{a: a}
+		}))
+		require.NoError(t, c.Invoke(func(*A) {}))
+
+		s := c.Snapshot()
+		require.NoError(t, c.Invoke(func(*B) {}))
+		require.NoError(t, c.Restore(s))
+
+		require.NoError(t, c.Invoke(func(b *B) {
+			assert.Equal(t, 1, b.a.idx, "values built before the snapshot are kept")
+		}))
+		assert.Equal(t, 1, aCalls)
+		assert.Equal(t, 2, bCalls)
+	})
+
+	t.Run("restore value groups", func(t *testing.T) {
+		c := New()
+		type out struct {
+			Out
+
+			A *A `group:"as"`
+		}
+		type in struct {
+			In
+
+			As []*A `group:"as"`
+		}
+		require.NoError(t, c.Provide(func() out { return out{A: &A{}} }))
+
+		s := c.Snapshot()
+		require.NoError(t, c.Invoke(func(in) {}))
+		require.NoError(t, c.Restore(s))
+		require.NoError(t, c.Invoke(func(i in) {
+			assert.Len(t, i.As, 1)
+		}))
+	})
+
+	t.Run("restore runtime information", func(t *testing.T) {
+		c := New(ProfileConstructors())
+		require.NoError(t, c.Provide(func() *A { return &A{} }))
+		require.NoError(t, c.Provide(func(*A) *B { return &B{} }))
+		require.NoError(t, c.Invoke(func(*A) {}))
+
+		s := c.Snapshot()
+		before := make([]nodeState, len(c.nodes))
+		for i, n := range c.nodes {
+			before[i] = n.state()
+		}
+
+		require.NoError(t, c.Invoke(func(*A, *B) {}))
+		require.NoError(t, c.Restore(s))
+		for i, n := range c.nodes {
+			assert.Equal(t, before[i], n.state())
+		}
+		assert.Len(t, c.invoked, 1)
+	})
+
+	t.Run("restore drops values of replaced constructors", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() *A { return &A{idx: 1} }))
+		require.NoError(t, c.Invoke(func(*A) {}))
+
+		s := c.Snapshot()
+		require.NoError(t, c.Replace(func() *A { return &A{idx: 2} }, Force()))
+		require.NoError(t, c.Restore(s))
+
+		require.NoError(t, c.Invoke(func(a *A) {
+			assert.Equal(t, 2, a.idx, "the replacing constructor must build the value")
+		}))
+	})
+
+	t.Run("restore errors", func(t *testing.T) {
+		c := New()
+		assert.Error(t, c.Restore(nil))
+		assert.Error(t, c.Restore(New().Snapshot()))
+	})
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"go.uber.org/dig/internal/dot"
+)
+
+// wiring is the part of an export compared by diff. Constructors are
+// identified by their display names since the IDs of the vertices depend on
+// the order in which constructors were provided.
+type wiring struct {
+	// Edges of each constructor, such as "provides *sql.DB".
+	ctors map[string]map[string]struct{}
+
+	// Aliases, as "alias -> target".
+	aliases map[string]struct{}
+}
+
+func newWiring(g *graph) *wiring {
+	w := &wiring{
+		ctors:   make(map[string]map[string]struct{}),
+		aliases: make(map[string]struct{}),
+	}
+	for _, v := range g.Vertices {
+		if v.Kind == dot.KindConstructor {
+			if _, ok := w.ctors[displayName(v)]; !ok {
+				w.ctors[displayName(v)] = make(map[string]struct{})
+			}
+		}
+	}
+	for _, e := range g.Edges {
+		switch e.Kind {
+		case dot.EdgeProvides:
+			w.ctors[g.name(e.From)]["provides "+g.name(e.To)] = struct{}{}
+		case dot.EdgeDepends:
+			dep := "depends on " + g.name(e.To)
+			if e.Optional {
+				dep += " (optional)"
+			}
+			w.ctors[g.name(e.From)][dep] = struct{}{}
+		case dot.EdgeAlias:
+			w.aliases[g.name(e.From)+" -> "+g.name(e.To)] = struct{}{}
+		}
+	}
+	return w
+}
+
+// printDiff prints the constructors added to or removed from the export old
+// in the export cur, the changes to the values they provide and depend on,
+// and the changes to aliases. It reports whether there were any changes.
+func printDiff(w io.Writer, old, cur *graph) bool {
+	before, after := newWiring(old), newWiring(cur)
+	changed := false
+
+	for _, name := range sortedKeys(before.ctors, after.ctors) {
+		oldEdges, inOld := before.ctors[name]
+		newEdges, inNew := after.ctors[name]
+		switch {
+		case !inOld:
+			fmt.Fprintf(w, "+ %v\n", name)
+			changed = true
+		case !inNew:
+			fmt.Fprintf(w, "- %v\n", name)
+			changed = true
+		}
+
+		var lines []string
+		for _, e := range sortedKeys(oldEdges, newEdges) {
+			_, wasThere := oldEdges[e]
+			_, isThere := newEdges[e]
+			switch {
+			case !wasThere:
+				lines = append(lines, "+ "+e)
+			case !isThere:
+				lines = append(lines, "- "+e)
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		if inOld && inNew {
+			fmt.Fprintf(w, "~ %v\n", name)
+		}
+		for _, l := range lines {
+			fmt.Fprintf(w, "    %v\n", l)
+		}
+		changed = true
+	}
+
+	for _, a := range sortedKeys(before.aliases, after.aliases) {
+		if _, ok := before.aliases[a]; !ok {
+			fmt.Fprintf(w, "+ alias %v\n", a)
+			changed = true
+		} else if _, ok := after.aliases[a]; !ok {
+			fmt.Fprintf(w, "- alias %v\n", a)
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// sortedKeys returns the sorted union of the keys of the given maps, which
+// are all map[string]struct{} or map[string]map[string]struct{}.
+func sortedKeys(maps ...interface{}) []string {
+	seen := make(map[string]struct{})
+	for _, m := range maps {
+		switch m := m.(type) {
+		case map[string]struct{}:
+			for k := range m {
+				seen[k] = struct{}{}
+			}
+		case map[string]map[string]struct{}:
+			for k := range m {
+				seen[k] = struct{}{}
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"go.uber.org/dig/internal/dot"
+)
+
+// graph is an export loaded for the commands, with the edges indexed in the
+// direction of dependencies: constructors depend on their parameters, values
+// on their constructors, groups on their values and aliases on their
+// targets.
+type graph struct {
+	*dot.Elements
+
+	deps  map[string][]string
+	users map[string][]string
+}
+
+func load(path string) (*graph, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	e, err := dot.ReadJSON(f)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %v: %v", path, err)
+	}
+	return newGraph(e), nil
+}
+
+func newGraph(e *dot.Elements) *graph {
+	g := &graph{
+		Elements: e,
+		deps:     make(map[string][]string),
+		users:    make(map[string][]string),
+	}
+	for _, edge := range e.Edges {
+		from, to := edge.From, edge.To
+		if edge.Kind == dot.EdgeProvides {
+			from, to = to, from
+		}
+		g.deps[from] = append(g.deps[from], to)
+		g.users[to] = append(g.users[to], from)
+	}
+	return g
+}
+
+// displayName returns the name of a vertex shown to users.
+func displayName(v *dot.Vertex) string {
+	switch v.Kind {
+	case dot.KindConstructor:
+		if v.Package == "" {
+			return v.Label
+		}
+		return v.Package + "." + v.Label
+	case dot.KindGroup:
+		return "[]" + v.Type + "[group=" + strconv.Quote(v.Group) + "]"
+	}
+
+	switch {
+	case v.Name != "":
+		return v.Type + "[name=" + strconv.Quote(v.Name) + "]"
+	case v.Group != "":
+		return v.Type + "[group=" + strconv.Quote(v.Group) + "]"
+	default:
+		return v.Type
+	}
+}
+
+// name returns the display name of the vertex with the given ID.
+func (g *graph) name(id string) string {
+	return displayName(g.Vertex(id))
+}
+
+// location returns the file and line of a constructor, if known.
+func location(v *dot.Vertex) string {
+	if v.File == "" {
+		return ""
+	}
+	return fmt.Sprintf("%v:%d", v.File, v.Line)
+}
+
+// find returns the IDs of the vertices matching the given query, which is a
+// display name, an ID or the type of values.
+func (g *graph) find(query string) ([]string, error) {
+	var ids []string
+	for _, v := range g.Vertices {
+		switch {
+		case v.ID == query, displayName(v) == query:
+		case v.Kind == dot.KindValue && v.Type == query:
+		default:
+			continue
+		}
+		ids = append(ids, v.ID)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no value or constructor matches %q", query)
+	}
+	return ids, nil
+}
+
+func (g *graph) isConstructor(id string) bool {
+	return g.Vertex(id).Kind == dot.KindConstructor
+}
+
+// sortByName sorts IDs by the display names of their vertices.
+func (g *graph) sortByName(ids []string) {
+	sort.SliceStable(ids, func(i, j int) bool {
+		return g.name(ids[i]) < g.name(ids[j])
+	})
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// digviz renders and compares the dependency graphs of dig containers
+// exported as JSON.
+//
+// Applications export their graph with dig.Visualize:
+//
+//   dig.Visualize(c, f, dig.VisualizeFormat(dig.FormatJSON), dig.VisualizeRuntime())
+//
+// digviz then works entirely offline on these exports:
+//
+//   digviz tree [-depth n] export.json [value ...]
+//   digviz unused [-root value ...] export.json
+//   digviz path export.json from to
+//   digviz diff old.json new.json
+//
+// Values are named by their type, such as *sql.DB, optionally followed by
+// their name or group as in *sql.DB[name="ro"]. Constructors are named by
+// their package and function, such as example.com/app/db.Open.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const _usage = `usage:
+  digviz tree [-depth n] export.json [value ...]
+	prints the values in the graph as trees of their dependencies,
+	starting with the given values or with the values nobody depends on
+  digviz unused [-root value ...] export.json
+	lists the constructors whose values are not needed by the roots, or
+	by any other constructor if no roots are given
+  digviz path export.json from to
+	prints the shortest chain of dependencies from a value or
+	constructor to another one
+  digviz diff old.json new.json
+	lists the constructors, dependencies and aliases added or removed
+	between two exports, and exits with status 1 if there are any
+`
+
+// errDifferent is returned by diff if the exports are different.
+var errDifferent = errors.New("exports are different")
+
+// errUsage is returned if the command line is invalid.
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	switch err := run(os.Args[1:], os.Stdout, os.Stderr); err {
+	case nil:
+	case errDifferent:
+		os.Exit(1)
+	case errUsage:
+		fmt.Fprint(os.Stderr, _usage)
+		os.Exit(2)
+	default:
+		fmt.Fprintln(os.Stderr, "digviz:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	flags := flag.NewFlagSet("digviz "+args[0], flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {}
+
+	switch args[0] {
+	case "tree":
+		depth := flags.Int("depth", 0, "maximum depth of the trees, unlimited if zero")
+		if err := flags.Parse(args[1:]); err != nil || flags.NArg() < 1 {
+			return errUsage
+		}
+		g, err := load(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		return g.printTrees(stdout, flags.Args()[1:], *depth)
+
+	case "unused":
+		var roots stringsFlag
+		flags.Var(&roots, "root", "value needed by the application, may be repeated")
+		if err := flags.Parse(args[1:]); err != nil || flags.NArg() != 1 {
+			return errUsage
+		}
+		g, err := load(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		return g.printUnused(stdout, roots)
+
+	case "path":
+		if err := flags.Parse(args[1:]); err != nil || flags.NArg() != 3 {
+			return errUsage
+		}
+		g, err := load(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		return g.printPath(stdout, flags.Arg(1), flags.Arg(2))
+
+	case "diff":
+		if err := flags.Parse(args[1:]); err != nil || flags.NArg() != 2 {
+			return errUsage
+		}
+		old, err := load(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		newer, err := load(flags.Arg(1))
+		if err != nil {
+			return err
+		}
+		if printDiff(stdout, old, newer) {
+			return errDifferent
+		}
+		return nil
+
+	default:
+		return errUsage
+	}
+}
+
+// stringsFlag is a flag that may be repeated.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string { return fmt.Sprint(*f) }
+
+func (f *stringsFlag) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
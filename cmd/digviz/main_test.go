@@ -0,0 +1,205 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+)
+
+type (
+	config  struct{}
+	db      struct{}
+	server  struct{}
+	handler struct{}
+	metrics struct{}
+)
+
+type serverParams struct {
+	dig.In
+
+	DB       *db       `name:"ro"`
+	Handlers []handler `group:"handlers"`
+}
+
+func newConfig() *config                    { return &config{} }
+func newDB(*config) *db                     { return &db{} }
+func newServer(serverParams) *server        { return &server{} }
+func newHandler() handler                   { return handler{} }
+func newMetrics() *metrics                  { return &metrics{} }
+func newMetricsFromConfig(*config) *metrics { return &metrics{} }
+
+// export writes the JSON export of a container built by provide to a
+// temporary file.
+func export(t *testing.T, dir, name string, provide func(c *dig.Container)) string {
+	c := dig.New()
+	provide(c)
+
+	var b bytes.Buffer
+	require.NoError(t, dig.Visualize(c, &b, dig.VisualizeFormat(dig.FormatJSON)))
+
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, b.Bytes(), 0644))
+	return path
+}
+
+func provideApp(c *dig.Container) {
+	c.Provide(newConfig)
+	c.Provide(newDB, dig.Names("ro", "main"))
+	c.Provide(newServer)
+	c.Provide(newHandler, dig.Group("handlers"))
+	c.Provide(newMetrics)
+}
+
+func digviz(t *testing.T, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	err := run(args, &stdout, &stderr)
+	return stdout.String(), err
+}
+
+func TestDigviz(t *testing.T) {
+	const pkg = "go.uber.org/dig/cmd/digviz."
+
+	dir, err := ioutil.TempDir("", "digviz")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	app := export(t, dir, "app.json", provideApp)
+
+	t.Run("tree", func(t *testing.T) {
+		out, err := digviz(t, "tree", app)
+		require.NoError(t, err)
+		assert.Equal(t, `*main.db[name="main"]
+  *main.db[name="ro"] <- `+pkg+`newDB
+    *main.config <- `+pkg+`newConfig
+*main.metrics <- `+pkg+`newMetrics
+*main.server <- `+pkg+`newServer
+  *main.db[name="ro"] <- `+pkg+`newDB ...
+  []main.handler[group="handlers"]
+    main.handler[group="handlers"] <- `+pkg+`newHandler
+`, out)
+	})
+
+	t.Run("tree of values with depth", func(t *testing.T) {
+		out, err := digviz(t, "tree", "-depth", "2", app, "*main.server")
+		require.NoError(t, err)
+		assert.Equal(t, `*main.server <- `+pkg+`newServer
+  *main.db[name="ro"] <- `+pkg+`newDB
+  []main.handler[group="handlers"]
+`, out)
+	})
+
+	t.Run("unused", func(t *testing.T) {
+		out, err := digviz(t, "unused", app)
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		require.Len(t, lines, 2)
+		assert.True(t, strings.HasPrefix(lines[0], pkg+"newMetrics (/"), lines[0])
+		assert.True(t, strings.HasPrefix(lines[1], pkg+"newServer (/"), lines[1])
+		assert.Contains(t, lines[0], "main_test.go:")
+	})
+
+	t.Run("unused with roots", func(t *testing.T) {
+		out, err := digviz(t, "unused", "-root", `*main.db[name="ro"]`, "-root", "main.handler[group=\"handlers\"]", app)
+		require.NoError(t, err)
+		assert.Contains(t, out, pkg+"newMetrics")
+		assert.Contains(t, out, pkg+"newServer")
+		assert.NotContains(t, out, pkg+"newDB")
+		assert.NotContains(t, out, pkg+"newHandler")
+	})
+
+	t.Run("path", func(t *testing.T) {
+		out, err := digviz(t, "path", app, "*main.server", "*main.config")
+		require.NoError(t, err)
+		assert.Equal(t, `*main.server
+-> `+pkg+`newServer
+-> *main.db[name="ro"]
+-> `+pkg+`newDB
+-> *main.config
+`, out)
+
+		_, err = digviz(t, "path", app, "*main.config", "*main.server")
+		require.Error(t, err)
+		assert.Equal(t, "*main.config does not depend on *main.server", err.Error())
+	})
+
+	t.Run("unknown value", func(t *testing.T) {
+		_, err := digviz(t, "path", app, "*main.config", "*main.missing")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `no value or constructor matches "*main.missing"`)
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		out, err := digviz(t, "diff", app, app)
+		require.NoError(t, err)
+		assert.Empty(t, out)
+
+		changed := export(t, dir, "changed.json", func(c *dig.Container) {
+			c.Provide(newConfig)
+			c.Provide(newDB, dig.Name("rw"))
+			c.Provide(newServer)
+			c.Provide(newHandler, dig.Group("handlers"))
+			c.Provide(newMetricsFromConfig)
+		})
+		out, err = digviz(t, "diff", app, changed)
+		assert.Equal(t, errDifferent, err)
+		assert.Equal(t, `~ `+pkg+`newDB
+    - provides *main.db[name="ro"]
+    + provides *main.db[name="rw"]
+- `+pkg+`newMetrics
+    - provides *main.metrics
++ `+pkg+`newMetricsFromConfig
+    + depends on *main.config
+    + provides *main.metrics
+- alias *main.db[name="main"] -> *main.db[name="ro"]
+`, out)
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		for _, args := range [][]string{
+			nil,
+			{"unknown"},
+			{"tree"},
+			{"path", app, "*main.server"},
+			{"diff", app},
+		} {
+			_, err := digviz(t, args...)
+			assert.Equal(t, errUsage, err, "%q", args)
+		}
+	})
+
+	t.Run("invalid export", func(t *testing.T) {
+		path := filepath.Join(dir, "invalid.json")
+		require.NoError(t, ioutil.WriteFile(path, []byte("digraph {}"), 0644))
+
+		_, err := digviz(t, "tree", path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot read "+path)
+	})
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"go.uber.org/dig/internal/dot"
+)
+
+// printTrees prints the dependencies of the values matching the queries, or
+// of the values nobody depends on, as indented trees. Values are followed by
+// the constructors that provide them. Subtrees already printed are elided.
+func (g *graph) printTrees(w io.Writer, queries []string, depth int) error {
+	var roots []string
+	for _, q := range queries {
+		ids, err := g.find(q)
+		if err != nil {
+			return err
+		}
+		roots = append(roots, ids...)
+	}
+	if len(queries) == 0 {
+		for _, v := range g.Vertices {
+			if len(g.users[v.ID]) == 0 {
+				roots = append(roots, v.ID)
+			}
+		}
+		g.sortByName(roots)
+	}
+
+	t := treePrinter{g: g, w: w, depth: depth, printed: make(map[string]bool)}
+	for _, id := range roots {
+		t.print(id, 0)
+	}
+	return nil
+}
+
+type treePrinter struct {
+	g       *graph
+	w       io.Writer
+	depth   int
+	printed map[string]bool
+}
+
+func (t *treePrinter) print(id string, level int) {
+	g := t.g
+
+	// The constructors of values are printed on the same line as the
+	// values, and their dependencies as the children of the values.
+	var ctors, children []string
+	for _, d := range g.deps[id] {
+		if g.isConstructor(d) && !g.isConstructor(id) {
+			ctors = append(ctors, g.name(d))
+			children = append(children, g.deps[d]...)
+		} else {
+			children = append(children, d)
+		}
+	}
+
+	line := strings.Repeat("  ", level) + g.name(id)
+	if len(ctors) > 0 {
+		line += " <- " + strings.Join(ctors, ", ")
+	}
+	if t.printed[id] && len(children) > 0 {
+		line += " ..."
+		children = nil
+	}
+	fmt.Fprintln(t.w, line)
+	t.printed[id] = true
+
+	if t.depth > 0 && level+1 >= t.depth {
+		return
+	}
+	for _, c := range children {
+		t.print(c, level+1)
+	}
+}
+
+// printUnused lists the constructors whose values are not needed by the
+// values matching the given roots. Without roots, it lists the constructors
+// whose values are not used by any other constructor, group or alias and
+// were not consumed at runtime, if the export has runtime information.
+func (g *graph) printUnused(w io.Writer, roots []string) error {
+	used := make(map[string]bool)
+	if len(roots) > 0 {
+		var stack []string
+		for _, r := range roots {
+			ids, err := g.find(r)
+			if err != nil {
+				return err
+			}
+			stack = append(stack, ids...)
+		}
+		for len(stack) > 0 {
+			id := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if used[id] {
+				continue
+			}
+			used[id] = true
+			stack = append(stack, g.deps[id]...)
+		}
+	} else {
+		// Follow the values needed by constructors through groups and
+		// aliases, up to the constructors of these values.
+		var stack []string
+		for _, v := range g.Vertices {
+			if v.Kind != dot.KindConstructor {
+				continue
+			}
+			if v.Runtime != nil && v.Runtime.Consumed > 0 {
+				used[v.ID] = true
+			}
+			stack = append(stack, g.deps[v.ID]...)
+		}
+		for len(stack) > 0 {
+			id := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if used[id] {
+				continue
+			}
+			used[id] = true
+			if !g.isConstructor(id) {
+				stack = append(stack, g.deps[id]...)
+			}
+		}
+	}
+
+	var unused []string
+	for _, v := range g.Vertices {
+		if v.Kind == dot.KindConstructor && !used[v.ID] {
+			unused = append(unused, v.ID)
+		}
+	}
+	g.sortByName(unused)
+
+	for _, id := range unused {
+		line := g.name(id)
+		if loc := location(g.Vertex(id)); loc != "" {
+			line += " (" + loc + ")"
+		}
+		fmt.Fprintln(w, line)
+	}
+	return nil
+}
+
+// printPath prints the shortest chain of dependencies from the vertices
+// matching from to the vertices matching to.
+func (g *graph) printPath(w io.Writer, from, to string) error {
+	starts, err := g.find(from)
+	if err != nil {
+		return err
+	}
+	ends, err := g.find(to)
+	if err != nil {
+		return err
+	}
+	isEnd := make(map[string]bool, len(ends))
+	for _, id := range ends {
+		isEnd[id] = true
+	}
+
+	prev := make(map[string]string)
+	queue := append([]string(nil), starts...)
+	for _, id := range starts {
+		prev[id] = ""
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if !isEnd[id] {
+			for _, d := range g.deps[id] {
+				if _, ok := prev[d]; !ok {
+					prev[d] = id
+					queue = append(queue, d)
+				}
+			}
+			continue
+		}
+
+		var path []string
+		for ; id != ""; id = prev[id] {
+			path = append([]string{g.name(id)}, path...)
+		}
+		fmt.Fprintln(w, strings.Join(path, "\n-> "))
+		return nil
+	}
+
+	return fmt.Errorf("%v does not depend on %v", from, to)
+}
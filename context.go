@@ -0,0 +1,189 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"go.uber.org/dig/internal/digreflect"
+)
+
+var _contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+type invokeOptions struct {
+	Context context.Context
+}
+
+type invokeOptionFunc func(*invokeOptions)
+
+func (f invokeOptionFunc) applyInvokeOption(opts *invokeOptions) { f(opts) }
+
+// InvokeContext is an InvokeOption that makes ctx available to the function
+// passed to Invoke and to the constructors called to build its
+// dependencies: their unnamed context.Context parameters receive ctx, even if
+// a context.Context was provided to the container.
+//
+//   c.Provide(func(ctx context.Context) (*sql.DB, error) {
+//     return connect(ctx)
+//   })
+//   err := c.Invoke(run, dig.InvokeContext(ctx))
+//
+// Once ctx is done, constructors which were not called yet are not started
+// and Invoke returns an error matching ctx.Err() with errors.Is.
+func InvokeContext(ctx context.Context) InvokeOption {
+	return invokeOptionFunc(func(opts *invokeOptions) {
+		opts.Context = ctx
+	})
+}
+
+// Timeout is a ProvideOption that bounds the time a constructor may take.
+// If the constructor does not return within d, the function that needs its
+// values fails with an error naming the constructor, which matches
+// context.DeadlineExceeded with errors.Is. Use IsTimeout to tell it apart
+// from other errors.
+//
+// The constructor keeps running in the background after the timeout, and
+// the values it returns are discarded. It is not marked as called, so it is
+// called again the next time its values are needed, possibly while the
+// first call is still running. A panic after the timeout is discarded too.
+func Timeout(d time.Duration) ProvideOption {
+	return provideOptionFunc(func(opts *provideOptions) {
+		opts.Timeout = d
+	})
+}
+
+// IsTimeout reports whether err, or an error it wraps, was caused by a
+// constructor exceeding the duration given to the Timeout option.
+func IsTimeout(err error) bool {
+	var timedOut errConstructorTimedOut
+	return errors.As(err, &timedOut)
+}
+
+func (c *Container) invokeContext() context.Context {
+	return c.ctx
+}
+
+// isInvokeContext reports whether the param receives the context of the
+// current Invoke.
+func isInvokeContext(c containerStore, ps paramSingle) bool {
+	return ps.Name == "" && ps.Type == _contextType && c.invokeContext() != nil
+}
+
+// checkCanceled returns errCanceled if the context of the current Invoke is
+// done.
+func checkCanceled(c containerStore, f *digreflect.Func) error {
+	ctx := c.invokeContext()
+	if ctx == nil {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return errCanceled{Func: f, Reason: err}
+	}
+	return nil
+}
+
+// callTimeout calls fn in a separate goroutine and returns
+// errConstructorTimedOut if it does not return within d. It returns early
+// if the context of the current Invoke is done.
+//
+// Panics in fn are always recovered in the goroutine, where they would
+// crash the program, and are raised again by callTimeout unless the
+// container recovers panics.
+func callTimeout(c containerStore, f *digreflect.Func, d time.Duration, fn func() ([]reflect.Value, error)) ([]reflect.Value, error) {
+	type result struct {
+		values []reflect.Value
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		var r result
+		defer func() { done <- r }()
+		defer recoverPanic(f, &r.err)
+		r.values, r.err = fn()
+	}()
+
+	var canceled <-chan struct{}
+	if ctx := c.invokeContext(); ctx != nil {
+		canceled = ctx.Done()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		if p, ok := r.err.(errConstructorPanicked); ok && !c.recoversPanics() {
+			panic(p.Panic)
+		}
+		return r.values, r.err
+	case <-timer.C:
+		return nil, errConstructorTimedOut{Func: f, Timeout: d}
+	case <-canceled:
+		return nil, errCanceled{Func: f, Reason: c.invokeContext().Err()}
+	}
+}
+
+// errConstructorTimedOut is returned when a constructor did not return within
+// the duration given to the Timeout option.
+type errConstructorTimedOut struct {
+	Func    *digreflect.Func
+	Timeout time.Duration
+}
+
+// Unwrap returns context.DeadlineExceeded.
+func (e errConstructorTimedOut) Unwrap() error { return context.DeadlineExceeded }
+
+func (e errConstructorTimedOut) Error() string { return fmt.Sprint(e) }
+
+func (e errConstructorTimedOut) Format(w fmt.State, c rune) {
+	verb := "%v"
+	if w.Flag('+') && c == 'v' {
+		verb = "%+v"
+	}
+	fmt.Fprintf(w, "function "+verb+" did not return within %v", e.Func, e.Timeout)
+}
+
+// errCanceled is returned when a function was not called, or a constructor
+// with a timeout was not waited for, because the context of the Invoke is
+// done.
+type errCanceled struct {
+	Func   *digreflect.Func
+	Reason error
+}
+
+var _ causer = errCanceled{}
+
+func (e errCanceled) cause() error { return e.Reason }
+
+func (e errCanceled) Unwrap() error { return e.Reason }
+
+func (e errCanceled) writeMessage(w io.Writer, verb string) {
+	fmt.Fprintf(w, "function "+verb+" was canceled", e.Func)
+}
+
+func (e errCanceled) Error() string { return fmt.Sprint(e) }
+func (e errCanceled) Format(w fmt.State, c rune) {
+	formatCauser(e, w, c)
+}
@@ -0,0 +1,214 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ctxKey struct{}
+
+func TestInvokeContext(t *testing.T) {
+	type A struct{ ctx context.Context }
+	type B struct{}
+
+	t.Run("context params", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), ctxKey{}, "invoke")
+		c := New()
+		require.NoError(t, c.Provide(func() context.Context { return context.Background() }))
+		require.NoError(t, c.Provide(func(ctx context.Context) *A { return &A{ctx} }))
+		require.NoError(t, c.Invoke(func(a *A, ctx context.Context) {
+			assert.Equal(t, "invoke", a.ctx.Value(ctxKey{}))
+			assert.Equal(t, "invoke", ctx.Value(ctxKey{}))
+		}, InvokeContext(ctx)))
+
+		require.NoError(t, c.Invoke(func(ctx context.Context) {
+			assert.Nil(t, ctx.Value(ctxKey{}), "provided context must be used without InvokeContext")
+		}))
+	})
+
+	t.Run("context not provided", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Invoke(func(context.Context) {}, InvokeContext(context.Background())))
+
+		err := c.Invoke(func(context.Context) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing type: context.Context")
+	})
+
+	t.Run("canceled before invoke", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		c := New()
+		require.NoError(t, c.Provide(func() *A {
+			t.Fatal("must not be called")
+			return nil
+		}))
+		err := c.Invoke(func(*A) {
+			t.Fatal("must not be called")
+		}, InvokeContext(ctx))
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.Contains(t, err.Error(), "TestInvokeContext.func3.1")
+		assert.Contains(t, err.Error(), "was canceled")
+	})
+
+	t.Run("canceled by a constructor", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		c := New()
+		require.NoError(t, c.Provide(func() *A {
+			cancel()
+			return &A{}
+		}))
+		require.NoError(t, c.Provide(func() *B {
+			t.Fatal("must not be called")
+			return nil
+		}))
+		err := c.Invoke(func(*A, *B) {}, InvokeContext(ctx))
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.Contains(t, err.Error(), "TestInvokeContext.func4.2")
+
+		var canceled *Report
+		for r := ErrorReport(err); r != nil; r = r.Cause {
+			if r.Kind == KindCanceled {
+				canceled = r
+			}
+		}
+		require.NotNil(t, canceled)
+		assert.Equal(t, "TestInvokeContext.func4.2", canceled.Func.Name)
+	})
+}
+
+func TestTimeout(t *testing.T) {
+	type A struct{}
+
+	t.Run("fast constructor", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() *A { return &A{} }, Timeout(time.Minute)))
+		require.NoError(t, c.Invoke(func(a *A) { assert.NotNil(t, a) }))
+	})
+
+	t.Run("slow constructor", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+
+		c := New()
+		require.NoError(t, c.Provide(func() *A {
+			<-release
+			return &A{}
+		}, Timeout(10*time.Millisecond)))
+
+		err := c.Invoke(func(*A) {})
+		require.Error(t, err)
+		assert.True(t, IsTimeout(err))
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.Contains(t, err.Error(), "TestTimeout.func2.1")
+		assert.Contains(t, err.Error(), "did not return within 10ms")
+
+		var timedOut *Report
+		for r := ErrorReport(err); r != nil; r = r.Cause {
+			if r.Kind == KindConstructorTimedOut {
+				timedOut = r
+			}
+		}
+		require.NotNil(t, timedOut)
+		assert.Equal(t, "TestTimeout.func2.1", timedOut.Func.Name)
+		assert.False(t, IsTimeout(errors.New("great sadness")))
+	})
+
+	t.Run("canceled while waiting", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		ctx, cancel := context.WithCancel(context.Background())
+
+		c := New()
+		require.NoError(t, c.Provide(func() *A {
+			cancel()
+			<-release
+			return &A{}
+		}, Timeout(time.Minute)))
+
+		err := c.Invoke(func(*A) {}, InvokeContext(ctx))
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.False(t, IsTimeout(err))
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		provide := func(c *Container) {
+			require.NoError(t, c.Provide(func() *A {
+				panic("great sadness")
+			}, Timeout(time.Minute)))
+		}
+
+		c := New()
+		provide(c)
+		assert.PanicsWithValue(t, "great sadness", func() {
+			c.Invoke(func(*A) {})
+		}, "panics must reach the caller of Invoke")
+
+		c = New(RecoverPanics(true))
+		provide(c)
+		err := c.Invoke(func(*A) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "panic in function")
+		assert.Contains(t, err.Error(), "great sadness")
+	})
+
+	t.Run("called again after a timeout", func(t *testing.T) {
+		release := make(chan struct{})
+		var calls int32
+
+		c := New()
+		require.NoError(t, c.Provide(func() *A {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				<-release
+			}
+			return &A{}
+		}, Timeout(10*time.Millisecond)))
+
+		err := c.Invoke(func(*A) {})
+		require.Error(t, err)
+		assert.True(t, IsTimeout(err))
+
+		require.NoError(t, c.Invoke(func(a *A) { assert.NotNil(t, a) }))
+		close(release)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("invalid timeout", func(t *testing.T) {
+		c := New()
+		err := c.Provide(func() *A { return &A{} }, Timeout(-time.Second))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "timeouts must not be negative")
+	})
+}
@@ -23,19 +23,26 @@ package dig
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"sort"
+	"strings"
 
 	"go.uber.org/dig/internal/digreflect"
+	"go.uber.org/dig/internal/dot"
 )
 
 type cycleEntry struct {
-	Key  key
-	Func *digreflect.Func
+	Key    key
+	Func   *digreflect.Func
+	CtorID dot.CtorID
 }
 
 type errCycleDetected struct {
 	Path []cycleEntry
 }
 
+var _ errVisualizer = errCycleDetected{}
+
 func (e errCycleDetected) Error() string {
 	// We get something like,
 	//
@@ -55,17 +62,75 @@ func (e errCycleDetected) Error() string {
 	return b.String()
 }
 
+// updateGraph marks every value of the cycle as a root cause: each of them
+// is as much to blame as the others.
+func (e errCycleDetected) updateGraph(g *dot.Graph) {
+	// The last entry is the same as the first one.
+	for _, entry := range e.Path[:len(e.Path)-1] {
+		g.StartFailure()
+		if entry.Key.group != "" {
+			g.FailGroupNodes(entry.Key.group, entry.Key.t, entry.CtorID)
+			continue
+		}
+		g.FailNodes([]*dot.Result{{
+			Node: &dot.Node{
+				Name: entry.Key.name,
+				Type: entry.Key.t,
+			},
+		}}, entry.CtorID)
+	}
+}
+
+// errCyclesDetected holds the cycles of a dependency graph with more than
+// one cycle.
+type errCyclesDetected []errCycleDetected // inv: len > 1
+
+var _ errVisualizer = errCyclesDetected{}
+
+func newErrCycles(cycles []errCycleDetected) error {
+	switch len(cycles) {
+	case 0:
+		return nil
+	case 1:
+		return cycles[0]
+	default:
+		return errCyclesDetected(cycles)
+	}
+}
+
+func (e errCyclesDetected) Error() string { return fmt.Sprint(e) }
+
+// Format implements fmt.Formatter. The cycles are always written on
+// multiple lines.
+func (e errCyclesDetected) Format(w fmt.State, c rune) {
+	fmt.Fprintf(w, "found %d cycles:", len(e))
+	for _, cycle := range e {
+		io.WriteString(w, "\n\t- ")
+		io.WriteString(w, strings.Replace(cycle.Error(), "\n", "\n\t  ", -1))
+	}
+}
+
+func (e errCyclesDetected) updateGraph(g *dot.Graph) {
+	for _, cycle := range e {
+		cycle.updateGraph(g)
+	}
+}
+
 // IsCycleDetected returns a boolean as to whether the provided error indicates
 // a cycle was detected in the container graph.
 func IsCycleDetected(err error) bool {
-	_, ok := RootCause(err).(errCycleDetected)
-	return ok
+	switch RootCause(err).(type) {
+	case errCycleDetected, errCyclesDetected:
+		return true
+	default:
+		return false
+	}
 }
 
 func verifyAcyclic(c containerStore, n provider, k key) error {
 	visited := make(map[key]struct{})
 	err := detectCycles(n, c, []cycleEntry{
-		{Key: k, Func: n.Location()},
+		{Key: k, Func: n.Location(), CtorID: n.ID()},
 	}, visited)
 	if err != nil {
 		err = errf("this function introduces a cycle", err)
@@ -73,6 +138,10 @@ func verifyAcyclic(c containerStore, n provider, k key) error {
 	return err
 }
 
+// detectCycles looks for a cycle leading back to the first element of path,
+// which is the new addition to the graph. It must therefore be in any cycle
+// that exists, assuming verifyAcyclic has been run for every previous
+// Provide.
 func detectCycles(n provider, c containerStore, path []cycleEntry, visited map[key]struct{}) error {
 	var err error
 	walkParam(n.ParamList(), paramVisitorFunc(func(param param) bool {
@@ -80,56 +149,25 @@ func detectCycles(n provider, c containerStore, path []cycleEntry, visited map[k
 			return false
 		}
 
-		var (
-			k         key
-			providers []provider
-		)
-		switch p := param.(type) {
-		case paramSingle:
-			k = key{name: p.Name, t: p.Type}
-			if _, ok := visited[k]; ok {
-				// We've already checked the dependencies for this type.
-				return false
-			}
-			providers = c.getValueProviders(p.Name, p.Type)
-		case paramGroupedSlice:
-			// NOTE: The key uses the element type, not the slice type.
-			k = key{group: p.Group, t: p.Type.Elem()}
-			if _, ok := visited[k]; ok {
-				// We've already checked the dependencies for this type.
-				return false
-			}
-			providers = c.getGroupProviders(p.Group, p.Type.Elem())
-		default:
+		k, providers, ok := paramProviders(c, param)
+		if !ok {
 			// Recurse for non-edge params.
 			return true
 		}
+		if _, ok := visited[k]; ok {
+			// We've already checked the dependencies for this type.
+			return false
+		}
+		visited[k] = struct{}{}
 
-		entry := cycleEntry{Func: n.Location(), Key: k}
-
-		if len(path) > 0 {
-			// Only mark a key as visited if path exists, i.e. this is not the
-			// first iteration through the c.verifyAcyclic() check. Otherwise the
-			// early exit from checking visited above will short circuit the
-			// cycle check below.
-			visited[k] = struct{}{}
-
-			// If it exists, the first element of path is the new addition to the
-			// graph, therefore it must be in any cycle that exists, assuming
-			// verifyAcyclic has been run for every previous Provide.
-			//
-			// Alternatively, if deferAcyclicVerification was set and detectCycles
-			// is only being called before the first Invoke, each node in the
-			// graph will be tested as the first element of the path, so any
-			// cycle that exists is guaranteed to trip the following condition.
-			if path[0].Key == k {
-				err = errCycleDetected{Path: append(path, entry)}
-				return false
-			}
+		if path[0].Key == k {
+			err = errCycleDetected{Path: append(path, path[0])}
+			return false
 		}
 
-		for _, n := range providers {
-			if e := detectCycles(n, c, append(path, entry), visited); e != nil {
+		for _, p := range providers {
+			entry := cycleEntry{Key: k, Func: p.Location(), CtorID: p.ID()}
+			if e := detectCycles(p, c, append(path, entry), visited); e != nil {
 				err = e
 				return false
 			}
@@ -140,3 +178,191 @@ func detectCycles(n provider, c containerStore, path []cycleEntry, visited map[k
 
 	return err
 }
+
+// paramProviders returns the key of the value requested by the given param
+// and its providers. ok is false for params which request more than one
+// value, like dig.In objects.
+func paramProviders(c containerStore, param param) (k key, providers []provider, ok bool) {
+	switch p := param.(type) {
+	case paramSingle:
+		return c.resolveAlias(key{name: p.Name, t: p.Type}), c.getValueProviders(p.Name, p.Type), true
+	case paramGroupedSlice:
+		// NOTE: The key uses the element type, not the slice type.
+		return key{group: p.Group, t: p.Type.Elem()}, c.getGroupProviders(p.Group, p.Type.Elem()), true
+	default:
+		return key{}, nil, false
+	}
+}
+
+// cycleVertex is a value of the dependency graph, as produced by one of its
+// providers.
+type cycleVertex struct {
+	Key      key
+	Provider provider
+}
+
+func (v cycleVertex) entry() cycleEntry {
+	return cycleEntry{Key: v.Key, Func: v.Provider.Location(), CtorID: v.Provider.ID()}
+}
+
+// findCycles finds the dependency cycles between the values produced by the
+// given providers. One cycle is returned for each strongly connected
+// component of the graph, that is, for each set of values which all depend on
+// each other. The cycles are sorted by the order of the providers.
+func findCycles(c containerStore, providers []provider) []errCycleDetected {
+	// This is Tarjan's strongly connected components algorithm.
+	var (
+		index   = make(map[cycleVertex]int) // discovery order of vertices
+		lowlink = make(map[cycleVertex]int)
+		onStack = make(map[cycleVertex]bool)
+		stack   []cycleVertex
+		edges   = make(map[cycleVertex][]cycleVertex)
+		sccs    [][]cycleVertex
+	)
+
+	edgesOf := func(v cycleVertex) []cycleVertex {
+		if es, ok := edges[v]; ok {
+			return es
+		}
+		es := []cycleVertex{}
+		walkParam(v.Provider.ParamList(), paramVisitorFunc(func(param param) bool {
+			k, providers, ok := paramProviders(c, param)
+			for _, p := range providers {
+				es = append(es, cycleVertex{Key: k, Provider: p})
+			}
+			return !ok
+		}))
+		edges[v] = es
+		return es
+	}
+
+	var connect func(v cycleVertex)
+	connect = func(v cycleVertex) {
+		index[v] = len(index)
+		lowlink[v] = index[v]
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range edgesOf(v) {
+			if _, ok := index[w]; !ok {
+				connect(w)
+				if lowlink[w] < lowlink[v] {
+					lowlink[v] = lowlink[w]
+				}
+			} else if onStack[w] && index[w] < lowlink[v] {
+				lowlink[v] = index[w]
+			}
+		}
+
+		if lowlink[v] != index[v] {
+			return
+		}
+		var scc []cycleVertex
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			scc = append(scc, w)
+			if w == v {
+				break
+			}
+		}
+		sccs = append(sccs, scc)
+	}
+
+	// Vertices are numbered in the order of the providers, so that the
+	// cycles are deterministic.
+	var (
+		vertices []cycleVertex
+		order    = make(map[cycleVertex]int)
+	)
+	for _, p := range providers {
+		for _, k := range resultKeys(p.ResultList()) {
+			v := cycleVertex{Key: k, Provider: p}
+			order[v] = len(vertices)
+			vertices = append(vertices, v)
+		}
+	}
+	for _, v := range vertices {
+		if _, ok := index[v]; !ok {
+			connect(v)
+		}
+	}
+
+	// Tarjan's algorithm finds dependencies first. Sort the cycles by their
+	// first vertex instead.
+	sort.Slice(sccs, func(i, j int) bool {
+		return rank(order, firstVertex(order, sccs[i])) < rank(order, firstVertex(order, sccs[j]))
+	})
+
+	var cycles []errCycleDetected
+	for _, scc := range sccs {
+		members := make(map[cycleVertex]struct{}, len(scc))
+		for _, v := range scc {
+			members[v] = struct{}{}
+		}
+		if path := shortestCycle(firstVertex(order, scc), members, edgesOf); path != nil {
+			cycles = append(cycles, errCycleDetected{Path: path})
+		}
+	}
+	return cycles
+}
+
+// firstVertex returns the vertex of the component that comes first in the
+// given order.
+func firstVertex(order map[cycleVertex]int, scc []cycleVertex) cycleVertex {
+	first := scc[0]
+	for _, v := range scc[1:] {
+		if rank(order, v) < rank(order, first) {
+			first = v
+		}
+	}
+	return first
+}
+
+// rank returns the position of the vertex in the given order. Vertices that
+// are not part of it come last.
+func rank(order map[cycleVertex]int, v cycleVertex) int {
+	if i, ok := order[v]; ok {
+		return i
+	}
+	return len(order)
+}
+
+// shortestCycle returns the shortest path from start back to itself through
+// the given vertices, or nil if there's none.
+func shortestCycle(
+	start cycleVertex,
+	members map[cycleVertex]struct{},
+	edgesOf func(cycleVertex) []cycleVertex,
+) []cycleEntry {
+	prev := make(map[cycleVertex]cycleVertex)
+	queue := []cycleVertex{start}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+
+		for _, w := range edgesOf(v) {
+			if _, ok := members[w]; !ok {
+				continue
+			}
+			if w == start {
+				path := []cycleEntry{start.entry()}
+				for u := v; u != start; u = prev[u] {
+					path = append(path, u.entry())
+				}
+				path = append(path, start.entry())
+				// The path was built backwards, except for its ends.
+				for i, j := 1, len(path)-2; i < j; i, j = i+1, j-1 {
+					path[i], path[j] = path[j], path[i]
+				}
+				return path
+			}
+			if _, ok := prev[w]; !ok {
+				prev[w] = v
+				queue = append(queue, w)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindCycles(t *testing.T) {
+	type (
+		A struct{}
+		B struct{}
+		C struct{}
+		D struct{}
+		E struct{}
+	)
+
+	t.Run("all cycles are reported", func(t *testing.T) {
+		// A <-> B, C <-> D <- E
+		c := New(DeferAcyclicVerification())
+		require.NoError(t, c.Provide(func(*B) *A { return &A{} }))
+		require.NoError(t, c.Provide(func(*A) *B { return &B{} }))
+		require.NoError(t, c.Provide(func(*D) *C { return &C{} }))
+		require.NoError(t, c.Provide(func(*C) *D { return &D{} }))
+		require.NoError(t, c.Provide(func(*D) *E { return &E{} }))
+
+		err := c.Invoke(func(*E) {})
+		require.Error(t, err)
+		assert.True(t, IsCycleDetected(err))
+
+		cycles, ok := RootCause(err).(errCyclesDetected)
+		require.True(t, ok, "expected multiple cycles, got %v", RootCause(err))
+		require.Len(t, cycles, 2)
+		assert.Equal(t, []string{"*dig.A", "*dig.B", "*dig.A"}, cycleKeys(cycles[0]))
+		assert.Equal(t, []string{"*dig.C", "*dig.D", "*dig.C"}, cycleKeys(cycles[1]))
+
+		assertErrorMatches(t, err,
+			`cycle detected in dependency graph:`,
+			`found 2 cycles:`,
+			`- \*dig.A provided by "go.uber.org/dig".TestFindCycles.func1.1 \(\S+\)`,
+			`depends on \*dig.B provided by "go.uber.org/dig".TestFindCycles.func1.2 \(\S+\)`,
+			`depends on \*dig.A provided by "go.uber.org/dig".TestFindCycles.func1.1 \(\S+\)`,
+			`- \*dig.C provided by "go.uber.org/dig".TestFindCycles.func1.3 \(\S+\)`,
+			`depends on \*dig.D provided by "go.uber.org/dig".TestFindCycles.func1.4 \(\S+\)`,
+			`depends on \*dig.C provided by "go.uber.org/dig".TestFindCycles.func1.3 \(\S+\)`,
+		)
+
+		r := ErrorReport(err).Cause
+		assert.Equal(t, KindCycles, r.Kind)
+		assert.Equal(t, "found 2 cycles", r.Message)
+		require.Len(t, r.Problems, 2)
+		assert.Equal(t, KindCycle, r.Problems[1].Kind)
+	})
+
+	t.Run("shortest cycle of a component", func(t *testing.T) {
+		// A -> B -> C -> A and A -> C
+		c := New(DeferAcyclicVerification())
+		require.NoError(t, c.Provide(func(*B, *C) *A { return &A{} }))
+		require.NoError(t, c.Provide(func(*C) *B { return &B{} }))
+		require.NoError(t, c.Provide(func(*A) *C { return &C{} }))
+
+		cycles := findCycles(c, providersOf(c))
+		require.Len(t, cycles, 1)
+		assert.Equal(t, []string{"*dig.A", "*dig.C", "*dig.A"}, cycleKeys(cycles[0]))
+	})
+
+	t.Run("self dependency through an alias", func(t *testing.T) {
+		c := New(DeferAcyclicVerification())
+		require.NoError(t, c.Provide(func(p struct {
+			In
+
+			A *A `name:"alias" optional:"true"`
+		}) *A {
+			return &A{}
+		}, Names("", "alias")))
+
+		cycles := findCycles(c, providersOf(c))
+		require.Len(t, cycles, 1)
+		assert.Equal(t, []string{"*dig.A", "*dig.A"}, cycleKeys(cycles[0]))
+	})
+
+	t.Run("value groups", func(t *testing.T) {
+		type out struct {
+			Out
+
+			A *A `group:"as"`
+		}
+		type in struct {
+			In
+
+			As []*A `group:"as"`
+		}
+
+		c := New(DeferAcyclicVerification())
+		require.NoError(t, c.Provide(func() out { return out{} }))
+		require.NoError(t, c.Provide(func(*B) out { return out{} }))
+		require.NoError(t, c.Provide(func(in) *B { return &B{} }))
+
+		cycles := findCycles(c, providersOf(c))
+		require.Len(t, cycles, 1)
+		assert.Equal(t, []string{`*dig.A[group="as"]`, "*dig.B", `*dig.A[group="as"]`}, cycleKeys(cycles[0]))
+		assert.Contains(t, cycles[0].Path[0].Func.Name, "TestFindCycles.func4.2")
+	})
+
+	t.Run("acyclic", func(t *testing.T) {
+		c := New(DeferAcyclicVerification())
+		require.NoError(t, c.Provide(func(*B, *C) *A { return &A{} }))
+		require.NoError(t, c.Provide(func(*C) *B { return &B{} }))
+		require.NoError(t, c.Provide(func() *C { return &C{} }))
+
+		assert.Empty(t, findCycles(c, providersOf(c)))
+		assert.NoError(t, c.Invoke(func(*A) {}))
+	})
+}
+
+func TestVisualizeCycles(t *testing.T) {
+	type (
+		A struct{}
+		B struct{}
+		C struct{}
+	)
+
+	c := New(DeferAcyclicVerification())
+	require.NoError(t, c.Provide(func(*B) *A { return &A{} }))
+	require.NoError(t, c.Provide(func(*A) *B { return &B{} }))
+	require.NoError(t, c.Provide(func() *C { return &C{} }))
+
+	err := c.Invoke(func(*A) {})
+	require.Error(t, err)
+
+	var b bytes.Buffer
+	require.NoError(t, Visualize(c, &b, VisualizeError(err)))
+	assert.Contains(t, b.String(), `"*dig.A" [color=red];`)
+	assert.Contains(t, b.String(), `"*dig.B" [color=red];`)
+	assert.NotContains(t, b.String(), `*dig.C`)
+}
+
+func providersOf(c *Container) []provider {
+	providers := make([]provider, len(c.nodes))
+	for i, n := range c.nodes {
+		providers[i] = n
+	}
+	return providers
+}
+
+func cycleKeys(e errCycleDetected) []string {
+	keys := make([]string, len(e.Path))
+	for i, entry := range e.Path {
+		keys[i] = fmt.Sprint(entry.Key)
+	}
+	return keys
+}
@@ -21,6 +21,7 @@
 package dig
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/rand"
@@ -60,8 +61,23 @@ type optionFunc func(*Container)
 func (f optionFunc) applyOption(c *Container) { f(c) }
 
 type provideOptions struct {
-	Name  string
-	Group string
+	Name    string
+	Group   string
+	Aliases []string
+	Force   bool
+	Timeout time.Duration
+
+	// Location reported for the constructor instead of where it is
+	// defined. It is set by helpers which provide closures of their own.
+	Location *digreflect.Func
+}
+
+// location returns the location reported for the given constructor.
+func (o *provideOptions) location(ctor interface{}) *digreflect.Func {
+	if o.Location != nil {
+		return o.Location
+	}
+	return digreflect.InspectFunc(ctor)
 }
 
 func (o *provideOptions) Validate() error {
@@ -81,6 +97,22 @@ func (o *provideOptions) Validate() error {
 	if strings.ContainsRune(o.Group, '`') {
 		return errf("invalid dig.Group(%q): group names cannot contain backquotes", o.Group)
 	}
+	if o.Force {
+		return errors.New("dig.Force() may only be used with Replace")
+	}
+	if o.Timeout < 0 {
+		return errf("invalid dig.Timeout(%v): timeouts must not be negative", o.Timeout)
+	}
+	if len(o.Aliases) > 0 && len(o.Group) > 0 {
+		return errf(
+			"cannot use aliases with value groups",
+			"aliases %q provided with group:%q", o.Aliases, o.Group)
+	}
+	for _, a := range o.Aliases {
+		if err := validateAliasName(a); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -128,10 +160,9 @@ func Group(group string) ProvideOption {
 	})
 }
 
-// An InvokeOption modifies the default behavior of Invoke. It's included for
-// future functionality; currently, there are no concrete implementations.
+// An InvokeOption modifies the default behavior of Invoke.
 type InvokeOption interface {
-	unimplemented()
+	applyInvokeOption(*invokeOptions)
 }
 
 // Container is a directed acyclic graph of types and their dependencies.
@@ -143,12 +174,20 @@ type Container struct {
 	// All nodes in the container.
 	nodes []*node
 
+	// Number of nodes ever added to the container, used to give each node
+	// a unique sequence number.
+	nodeSeq uintptr
+
 	// Values that have already been generated in the container.
 	values map[key]reflect.Value
 
 	// Values groups that have already been generated in the container.
 	groups map[key][]reflect.Value
 
+	// Mapping from an alias to the key it refers to. The referred key is
+	// never an alias itself.
+	aliases map[key]key
+
 	// Source of randomness.
 	rand *rand.Rand
 
@@ -163,9 +202,53 @@ type Container struct {
 
 	graph *injectGraph
 
+	// Functions successfully called by Invoke, in the order they were
+	// first called, once each. They are the roots of the chains reported by
+	// Explain.
+	invoked []invocation
+
+	// Records the chain of calls leading to each constructor when the
+	// ProfileConstructors option is used, nil otherwise.
+	callStack *callStack
+
+	// Handlers receiving the events emitted by the container.
+	eventHandlers []EventHandler
+
+	// Hooks wrapping the calls to constructors and invoked functions.
+	hooks []CallHook
+
+	// Whether panics in constructors and invoked functions are returned as
+	// errors.
+	recoverPanics bool
+
+	// Context given to the Invoke in progress with InvokeContext, if any.
+	ctx context.Context
+
 	*containerExt
 }
 
+// invocation is a function passed to Invoke.
+type invocation struct {
+	location  *digreflect.Func
+	paramList paramList
+}
+
+// addInvocation records a function successfully called by Invoke, unless a
+// function of the same type defined at the same location was already
+// recorded. The type tells apart functions built with reflect.MakeFunc,
+// which are all reported at the same location.
+func (c *Container) addInvocation(loc *digreflect.Func, pl paramList) {
+	for _, inv := range c.invoked {
+		if inv.paramList.ctype == pl.ctype && newLocation(inv.location) == newLocation(loc) {
+			return
+		}
+	}
+	c.invoked = append(c.invoked, invocation{
+		location:  loc,
+		paramList: pl,
+	})
+}
+
 // containerWriter provides write access to the Container's underlying data
 // store.
 type containerWriter interface {
@@ -186,6 +269,14 @@ type containerStore interface {
 	// Returns a slice containing all known types.
 	knownTypes() []reflect.Type
 
+	// Returns the keys of all values that can be produced, aliases included,
+	// in a deterministic order.
+	knownKeys() []key
+
+	// Reports whether a PassiveProvide fallback produces values of the given
+	// type.
+	hasPassiveProvider(t reflect.Type) bool
+
 	// Retrieves the value with the provided name and type, if any.
 	getValue(name string, t reflect.Type) (v reflect.Value, ok bool)
 
@@ -204,9 +295,34 @@ type containerStore interface {
 
 	createGraph() *dot.Graph
 
+	// Returns the key that the given key is an alias for, or the key itself
+	// if it is not an alias.
+	resolveAlias(k key) key
+
 	// Returns invokerFn function to use when calling arguments.
 	invoker() invokerFn
 
+	// Returns the hooks to call functions through, outermost first.
+	callHooks() []CallHook
+
+	// Reports whether panics of the functions called by the container are
+	// recovered.
+	recoversPanics() bool
+
+	// Returns the context given to the Invoke in progress, or nil.
+	invokeContext() context.Context
+
+	// Returns the stack of functions being called, or nil if constructors
+	// are not profiled.
+	profiler() *callStack
+
+	// Reports whether the container has event handlers. Events need not
+	// be built otherwise.
+	handlesEvents() bool
+
+	// Delivers an event to the event handlers of the container.
+	emit(Event)
+
 	intercept(p param) error
 }
 
@@ -232,6 +348,10 @@ type provider interface {
 	// The values produced by this provider should be submitted into the
 	// containerStore.
 	Call(containerStore) error
+
+	// Consumed records that a value produced by this provider was passed to
+	// a function.
+	Consumed()
 }
 
 // New constructs a Container.
@@ -240,6 +360,7 @@ func New(opts ...Option) *Container {
 		providers:    make(map[key][]*node),
 		values:       make(map[key]reflect.Value),
 		groups:       make(map[key][]reflect.Value),
+		aliases:      make(map[key]key),
 		rand:         rand.New(rand.NewSource(time.Now().UnixNano())),
 		invokerFn:    defaultInvoker,
 		graph:        newInjectGraph(),
@@ -318,8 +439,27 @@ func (c *Container) knownTypes() []reflect.Type {
 	return types
 }
 
+func (c *Container) knownKeys() []key {
+	keys := make([]key, 0, len(c.providers)+len(c.aliases))
+	for k := range c.providers {
+		keys = append(keys, k)
+	}
+	for a := range c.aliases {
+		keys = append(keys, a)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+	return keys
+}
+
+func (c *Container) hasPassiveProvider(t reflect.Type) bool {
+	_, ok := c.passives[key{t: t}]
+	return ok
+}
+
 func (c *Container) getValue(name string, t reflect.Type) (v reflect.Value, ok bool) {
-	v, ok = c.values[key{name: name, t: t}]
+	v, ok = c.values[c.resolveAlias(key{name: name, t: t})]
 	return
 }
 
@@ -339,7 +479,7 @@ func (c *Container) submitGroupedValue(name string, t reflect.Type, v reflect.Va
 }
 
 func (c *Container) getValueProviders(name string, t reflect.Type) []provider {
-	return c.getProviders(key{name: name, t: t})
+	return c.getProviders(c.resolveAlias(key{name: name, t: t}))
 }
 
 func (c *Container) getGroupProviders(name string, t reflect.Type) []provider {
@@ -361,6 +501,10 @@ func (c *Container) invoker() invokerFn {
 	return c.invokerFn
 }
 
+func (c *Container) profiler() *callStack {
+	return c.callStack
+}
+
 // Provide teaches the container how to build values of one or more types and
 // expresses their dependencies.
 //
@@ -394,13 +538,24 @@ func (c *Container) Provide(constructor interface{}, opts ...ProvideOption) erro
 		return err
 	}
 
-	if err := c.provide(constructor, options); err != nil {
-		return errProvide{
-			Func:   digreflect.InspectFunc(constructor),
-			Reason: err,
+	var loc Location
+	events := c.handlesEvents()
+	if events {
+		loc = newLocation(options.location(constructor))
+		c.emit(&ProvideStart{Func: loc})
+	}
+
+	var err error
+	if perr := c.provide(constructor, options); perr != nil {
+		err = errProvide{
+			Func:   options.location(constructor),
+			Reason: perr,
 		}
 	}
-	return nil
+	if events {
+		c.emit(&ProvideDone{Func: loc, Err: err})
+	}
+	return err
 }
 
 // Invoke runs the given function after instantiating its dependencies.
@@ -420,66 +575,118 @@ func (c *Container) Invoke(function interface{}, opts ...InvokeOption) error {
 		return errf("can't invoke non-function %v (type %v)", function, ftype)
 	}
 
-	pl, err := newParamList(ftype)
+	_, err := c.invokeFunc(reflect.ValueOf(function), digreflect.InspectFunc(function), opts)
+	return err
+}
+
+// invokeFunc runs a function after instantiating its dependencies, and
+// returns its results if it succeeded. It is the implementation of Invoke,
+// InvokeResults and Get.
+func (c *Container) invokeFunc(fn reflect.Value, loc *digreflect.Func, opts []InvokeOption) ([]reflect.Value, error) {
+	var options invokeOptions
+	for _, o := range opts {
+		o.applyInvokeOption(&options)
+	}
+	if options.Context != nil {
+		prev := c.ctx
+		c.ctx = options.Context
+		defer func() { c.ctx = prev }()
+	}
+
+	if !c.handlesEvents() {
+		return c.invoke(fn, loc)
+	}
+	c.emit(&InvokeStart{Func: newLocation(loc)})
+	returned, err := c.invoke(fn, loc)
+	c.emit(&InvokeDone{Func: newLocation(loc), Err: err})
+	return returned, err
+}
+
+// invoke runs a function passed to Invoke. It also runs the functions built
+// by Get and passed to InvokeResults, through invokeFunc.
+func (c *Container) invoke(fn reflect.Value, loc *digreflect.Func) ([]reflect.Value, error) {
+	pl, err := newParamList(fn.Type())
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if c.callStack != nil {
+		c.callStack.push(loc)
+		defer c.callStack.pop()
 	}
 
 	// 拦截检查
 	if err := c.intercept(pl); err != nil {
-		return errMissingDependencies{
-			Func:   digreflect.InspectFunc(function),
+		return nil, errMissingDependencies{
+			Func:   loc,
 			Reason: err,
 		}
 	}
 
 	if err := shallowCheckDependencies(c, pl); err != nil {
-		return errMissingDependencies{
-			Func:   digreflect.InspectFunc(function),
+		return nil, errMissingDependencies{
+			Func:   loc,
 			Reason: err,
 		}
 	}
 
 	if !c.isVerifiedAcyclic {
 		if err := c.verifyAcyclic(); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
 	args, err := pl.BuildList(c)
 	if err != nil {
-		return errArgumentsFailed{
-			Func:   digreflect.InspectFunc(function),
+		return nil, errArgumentsFailed{
+			Func:   loc,
 			Reason: err,
 		}
 	}
 
 	if err := c.populateArgs(args); err != nil {
-		return errArgumentsFailed{
-			Func:   digreflect.InspectFunc(function),
+		return nil, errArgumentsFailed{
+			Func:   loc,
 			Reason: err,
 		}
 	}
 
-	returned := c.invokerFn(reflect.ValueOf(function), args)
+	if err := checkCanceled(c, loc); err != nil {
+		return nil, err
+	}
+
+	// The description of the call is only needed by hooks.
+	info := CallInfo{Invoke: true, Args: args}
+	if len(c.hooks) > 0 {
+		info.Func = newLocation(loc)
+		info.Params = keyInfos(paramKeys(pl))
+	}
+	returned, err := call(c, loc, info, fn)
+	if _, ok := err.(errConstructorPanicked); ok {
+		return nil, err
+	} else if err != nil {
+		return nil, errf("failed to call %v", loc, err)
+	}
 	if len(returned) == 0 {
-		return nil
+		c.addInvocation(loc, pl)
+		return nil, nil
 	}
 	if last := returned[len(returned)-1]; isError(last.Type()) {
 		if err, _ := last.Interface().(error); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	return nil
+	c.addInvocation(loc, pl)
+	return returned, nil
 }
 
 func (c *Container) verifyAcyclic() error {
-	visited := make(map[key]struct{})
-	for _, n := range c.nodes {
-		if err := detectCycles(n, c, nil /* path */, visited); err != nil {
-			return errf("cycle detected in dependency graph", err)
-		}
+	providers := make([]provider, len(c.nodes))
+	for i, n := range c.nodes {
+		providers[i] = n
+	}
+	if err := newErrCycles(findCycles(c, providers)); err != nil {
+		return errf("cycle detected in dependency graph", err)
 	}
 
 	c.isVerifiedAcyclic = true
@@ -490,8 +697,11 @@ func (c *Container) provide(ctor interface{}, opts provideOptions) error {
 	n, err := newNode(
 		ctor,
 		nodeOptions{
-			ResultName:  opts.Name,
-			ResultGroup: opts.Group,
+			ResultName:    opts.Name,
+			ResultGroup:   opts.Group,
+			ResultAliases: opts.Aliases,
+			Timeout:       opts.Timeout,
+			Location:      opts.Location,
 		},
 	)
 	if err != nil {
@@ -501,7 +711,7 @@ func (c *Container) provide(ctor interface{}, opts provideOptions) error {
 }
 
 func (c *Container) provideNode(ctor interface{}, n *node) error {
-	keys, err := c.findAndValidateResults(n)
+	keys, aliases, err := c.findAndValidateResults(n)
 	if err != nil {
 		return err
 	}
@@ -511,6 +721,12 @@ func (c *Container) provideNode(ctor interface{}, n *node) error {
 		return errf("%v must provide at least one non-error type", ctype)
 	}
 
+	// Aliases must be visible to cycle detection: the constructor may depend
+	// on one of its own results through an alias.
+	for a, k := range aliases {
+		c.aliases[a] = k
+	}
+
 	for k := range keys {
 		c.isVerifiedAcyclic = false
 		oldProviders := c.providers[k]
@@ -521,36 +737,44 @@ func (c *Container) provideNode(ctor interface{}, n *node) error {
 		}
 		if err := verifyAcyclic(c, n, k); err != nil {
 			c.providers[k] = oldProviders
+			for a := range aliases {
+				delete(c.aliases, a)
+			}
 			return err
 		}
 		c.isVerifiedAcyclic = true
 	}
 
+	c.nodeSeq++
+	n.seq = c.nodeSeq
 	c.nodes = append(c.nodes, n)
 
 	return nil
 }
 
-// Builds a collection of all result types produced by this node.
-func (c *Container) findAndValidateResults(n *node) (map[key]struct{}, error) {
+// Builds a collection of all result types produced by this node, along with
+// the aliases requested for those results.
+func (c *Container) findAndValidateResults(n *node) (map[key]struct{}, map[key]key, error) {
 	var err error
 	keyPaths := make(map[key]string)
+	aliases := make(map[key]key)
 	walkResult(n.ResultList(), connectionVisitor{
 		c:        c,
 		n:        n,
 		err:      &err,
 		keyPaths: keyPaths,
+		aliases:  aliases,
 	})
 
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	keys := make(map[key]struct{}, len(keyPaths))
 	for k := range keyPaths {
 		keys[k] = struct{}{}
 	}
-	return keys, nil
+	return keys, aliases, nil
 }
 
 // Visits the results of a node and compiles a collection of all the keys
@@ -572,6 +796,10 @@ type connectionVisitor struct {
 	// constructor.
 	keyPaths map[key]string
 
+	// Map of aliases requested for the results of this node to the keys
+	// they refer to.
+	aliases map[key]key
+
 	// We track the path to the current result here. For example, this will
 	// be, ["[1]", "Foo", "Bar"] when we're visiting Bar in,
 	//
@@ -585,10 +813,15 @@ type connectionVisitor struct {
 	//     }
 	//   })
 	currentResultPath []string
+
+	// Whether the current result is a field of a result object. Aliases
+	// requested with Names do not apply to such fields.
+	inObject bool
 }
 
 func (cv connectionVisitor) AnnotateWithField(f resultObjectField) resultVisitor {
 	cv.currentResultPath = append(cv.currentResultPath, f.FieldName)
+	cv.inObject = true
 	return cv
 }
 
@@ -617,21 +850,38 @@ func (cv connectionVisitor) Visit(res result) resultVisitor {
 			return nil
 		}
 
-		if ps := cv.c.providers[k]; len(ps) > 0 {
-			cons := make([]string, len(ps))
-			for i, p := range ps {
-				cons[i] = fmt.Sprint(p.Location())
-			}
-
-			*cv.err = errf(
-				"cannot provide %v from %v", k, path,
-				"already provided by %v", strings.Join(cons, "; "),
-			)
+		if err := cv.checkProvided(k); err != nil {
+			*cv.err = errf("cannot provide %v from %v", k, path, err)
 			return nil
 		}
 
 		cv.keyPaths[k] = path
 
+		if cv.inObject {
+			break
+		}
+		for _, name := range cv.n.aliases {
+			a := key{name: name, t: r.Type}
+			if conflict, ok := cv.keyPaths[a]; ok {
+				*cv.err = errf(
+					"cannot alias %v as %v from %v", k, a, path,
+					"already provided by %v", conflict,
+				)
+				return nil
+			}
+			if _, ok := cv.aliases[a]; ok {
+				*cv.err = errf(
+					"cannot alias %v as %v from %v", k, a, path,
+					"alias requested more than once")
+				return nil
+			}
+			if err := cv.checkProvided(a); err != nil {
+				*cv.err = errf("cannot alias %v as %v from %v", k, a, path, err)
+				return nil
+			}
+			cv.aliases[a] = k
+		}
+
 	case resultGrouped:
 		// we don't really care about the path for this since conflicts are
 		// okay for group results. We'll track it for the sake of having a
@@ -643,6 +893,24 @@ func (cv connectionVisitor) Visit(res result) resultVisitor {
 	return cv
 }
 
+// checkProvided returns an error if the given key is already provided by
+// another constructor or is an alias for another key.
+func (cv connectionVisitor) checkProvided(k key) error {
+	if ps := cv.c.providers[k]; len(ps) > 0 {
+		cons := make([]string, len(ps))
+		for i, p := range ps {
+			cons[i] = fmt.Sprint(p.Location())
+		}
+		return errf("already provided by %v", strings.Join(cons, "; "))
+	}
+
+	if target, ok := cv.c.aliases[k]; ok {
+		return errf("already an alias for %v", target)
+	}
+
+	return nil
+}
+
 // node is a node in the dependency graph. Each node maps to a single
 // constructor provided by the user.
 //
@@ -656,8 +924,11 @@ type node struct {
 	// Location where this function was defined.
 	location *digreflect.Func
 
-	// id uniquely identifies the constructor that produces a node.
-	id dot.CtorID
+	// Unique number of this node in its container, in the order the nodes
+	// were provided. It is also the ID of the constructor in graphs, which
+	// must differ even if the same function is provided several times or
+	// several closures share their code.
+	seq uintptr
 
 	// Whether the constructor owned by this node was already called.
 	called bool
@@ -667,6 +938,31 @@ type node struct {
 
 	// Type information about constructor results.
 	resultList resultList
+
+	// Additional names under which the values produced by this node are
+	// available.
+	aliases []string
+
+	// Whether this node was generated by PassiveProvide.
+	passive bool
+
+	// Runtime information shown by Visualize with the VisualizeRuntime
+	// option: the time spent in the constructor, not including its
+	// dependencies, which is only measured by ProfileConstructors, how many
+	// times its values were passed to functions, and whether they were used
+	// to populate inject-tagged fields.
+	duration time.Duration
+	consumed int
+	injected bool
+
+	// Time spent in Call, including building the dependencies that were
+	// not built yet, and the functions whose calls led to this one,
+	// outermost first. Both are only recorded by ProfileConstructors.
+	total   time.Duration
+	callers []*digreflect.Func
+
+	// Maximum time the constructor may take, if positive.
+	timeout time.Duration
 }
 
 type nodeOptions struct {
@@ -674,12 +970,22 @@ type nodeOptions struct {
 	// or belong to the specified value group
 	ResultName  string
 	ResultGroup string
+
+	// If specified, all values produced by this node are also available
+	// under these names.
+	ResultAliases []string
+
+	// If positive, the constructor fails if it does not return within
+	// this duration.
+	Timeout time.Duration
+
+	// If specified, the location reported for the constructor instead of
+	// where it is defined.
+	Location *digreflect.Func
 }
 
 func newNode(ctor interface{}, opts nodeOptions) (*node, error) {
-	cval := reflect.ValueOf(ctor)
-	ctype := cval.Type()
-	cptr := cval.Pointer()
+	ctype := reflect.TypeOf(ctor)
 
 	params, err := newParamList(ctype)
 	if err != nil {
@@ -697,20 +1003,27 @@ func newNode(ctor interface{}, opts nodeOptions) (*node, error) {
 		return nil, err
 	}
 
+	loc := opts.Location
+	if loc == nil {
+		loc = digreflect.InspectFunc(ctor)
+	}
+
 	return &node{
 		ctor:       ctor,
 		ctype:      ctype,
-		location:   digreflect.InspectFunc(ctor),
-		id:         dot.CtorID(cptr),
+		location:   loc,
 		paramList:  params,
 		resultList: results,
+		aliases:    opts.ResultAliases,
+		timeout:    opts.Timeout,
 	}, err
 }
 
 func (n *node) Location() *digreflect.Func { return n.location }
 func (n *node) ParamList() paramList       { return n.paramList }
 func (n *node) ResultList() resultList     { return n.resultList }
-func (n *node) ID() dot.CtorID             { return n.id }
+func (n *node) ID() dot.CtorID             { return dot.CtorID(n.seq) }
+func (n *node) Consumed()                  { n.consumed++ }
 
 // Call calls this node's constructor if it hasn't already been called and
 // injects any values produced by it into the provided container.
@@ -719,6 +1032,15 @@ func (n *node) Call(c containerStore) error {
 		return nil
 	}
 
+	// Calls are only timed when profiling.
+	var begin time.Time
+	p := c.profiler()
+	if p != nil {
+		begin = time.Now()
+		n.callers = p.push(n.location)
+		defer p.pop()
+	}
+
 	if err := shallowCheckDependencies(c, n.paramList); err != nil {
 		return errMissingDependencies{
 			Func:   n.location,
@@ -734,13 +1056,67 @@ func (n *node) Call(c containerStore) error {
 		}
 	}
 
+	if err := checkCanceled(c, n.location); err != nil {
+		return err
+	}
+
+	// Events are only built if someone listens to them, and constructors
+	// are also timed for them.
+	events := c.handlesEvents()
+	if events {
+		c.emit(&ConstructorStart{Func: newLocation(n.location), Results: resultInfos(n)})
+	}
+
 	receiver := newStagingContainerWriter()
-	results := c.invoker()(reflect.ValueOf(n.ctor), args)
-	if err := n.resultList.ExtractList(receiver, results); err != nil {
+	var start time.Time
+	if p != nil || events {
+		start = time.Now()
+	}
+	// The description of the call is only needed by hooks.
+	info := CallInfo{Args: args}
+	if len(c.callHooks()) > 0 {
+		info.Func = newLocation(n.location)
+		info.Params = keyInfos(paramKeys(n.paramList))
+		info.Results = resultInfos(n)
+	}
+	var returned []reflect.Value
+	if n.timeout > 0 {
+		returned, err = callTimeout(c, n.location, n.timeout, func() ([]reflect.Value, error) {
+			return call(c, n.location, info, reflect.ValueOf(n.ctor))
+		})
+	} else {
+		returned, err = call(c, n.location, info, reflect.ValueOf(n.ctor))
+	}
+	var d time.Duration
+	if p != nil || events {
+		d = time.Since(start)
+	}
+	if p != nil {
+		n.duration = d
+	}
+	if err == nil {
+		err = n.resultList.ExtractList(receiver, returned)
+	}
+	if events {
+		c.emit(&ConstructorDone{
+			Func:     newLocation(n.location),
+			Results:  resultInfos(n),
+			Duration: d,
+			Err:      err,
+		})
+	}
+	switch err.(type) {
+	case nil:
+	case errConstructorPanicked, errConstructorTimedOut, errCanceled:
+		return err
+	default:
 		return errConstructorFailed{Func: n.location, Reason: err}
 	}
 	receiver.Commit(c)
 	n.called = true
+	if p != nil {
+		n.total = time.Since(begin)
+	}
 
 	return nil
 }
@@ -792,6 +1168,9 @@ func shallowCheckDependencies(c containerStore, p param) error {
 			return true
 		}
 
+		if isInvokeContext(c, ps) {
+			return true
+		}
 		if ns := c.getValueProviders(ps.Name, ps.Type); len(ns) == 0 && !ps.Optional {
 			err = append(err, newErrMissingTypes(c, key{name: ps.Name, t: ps.Type})...)
 			addMissingNodes = append(addMissingNodes, ps.DotParam()...)
@@ -8,13 +8,24 @@ import (
 )
 
 type containerExt struct {
-	intercepts map[key]func(p param) error
+	intercepts map[key]interceptor
+	passives   map[key]passiveProvider
 	uuid       int
 }
 
+// passiveProvider 记录 PassiveProvide 的 constructor，供 Verify 静态检查其依赖
+type passiveProvider struct {
+	ctor           interface{}
+	nameParamIndex int
+}
+
+// interceptor 在容器找不到依赖时被调用，c 为当前发生拦截的容器
+type interceptor func(c *Container, p param) error
+
 func newContainerExt() *containerExt {
 	return &containerExt{
-		intercepts: make(map[key]func(p param) error),
+		intercepts: make(map[key]interceptor),
+		passives:   make(map[key]passiveProvider),
 	}
 }
 
@@ -74,7 +85,8 @@ func (c *Container) PassiveProvide(constructor interface{}, opt ...PassiveProvid
 	// 映射 retType 与其执行的信息
 	retType := ctype.Out(opts.ResultIndex) // 返回值类型
 	k := key{t: retType}
-	c.intercepts[k] = func(p param) error {
+	c.passives[k] = passiveProvider{ctor: constructor, nameParamIndex: opts.NameParamIndex}
+	c.intercepts[k] = func(c *Container, p param) error {
 		// param 描述了一个依赖，正是 constructor 的result提供的
 		// 这里将 constructor 处理后提供给容器，完成这个依赖
 
@@ -98,13 +110,22 @@ func (c *Container) PassiveProvide(constructor interface{}, opt ...PassiveProvid
 		node.paramList.Params[opts.NameParamIndex] = nameParam // 覆盖
 		c.uuid++
 		// 将 name 也提供给容器
-		if err := c.Provide(func() string { return ps.Name }, Name(nameParam.Name)); err != nil {
+		//  直接调用 provide，不对外发出 Provide 事件
+		if err := c.provide(func() string { return ps.Name }, provideOptions{Name: nameParam.Name}); err != nil {
 			return err
 		}
 
+		node.passive = true // 供 VisualizeRuntime 标记
+
 		// 处理完成，提供给容器
 		// 相当于: Provide(constructor,dig.Name(param.name))
-		return c.provideNode(constructor, node)
+		if err := c.provideNode(constructor, node); err != nil {
+			return err
+		}
+		if c.handlesEvents() {
+			c.emit(&PassiveResolved{Name: ps.Name, Type: ps.Type})
+		}
+		return nil
 	}
 
 	return nil
@@ -128,7 +149,7 @@ func (c *Container) intercept(p param) error {
 		}
 
 		if f, ok := c.intercepts[key{t: ps.Type}]; ok {
-			if e := f(ps); e != nil {
+			if e := f(c, ps); e != nil {
 				err = e
 				return false
 			}
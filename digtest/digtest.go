@@ -0,0 +1,286 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package digtest provides assertions to pin the shape of a dig.Container
+// in unit tests.
+//
+// None of the assertions call the constructors provided to the container.
+// They operate on a dry-run clone of the container instead, so they are safe
+// to use on containers whose constructors open connections or start
+// servers.
+//
+//   func TestWiring(t *testing.T) {
+//     c := app.NewContainer()
+//     digtest.AssertResolvable(t, c, new(*http.Server))
+//     digtest.AssertResolvable(t, c, digtest.Named(new(*sql.DB), "ro"))
+//     digtest.AssertNoUnusedProviders(t, c, app.Run)
+//     digtest.AssertGraphGolden(t, c, "testdata/app.dot")
+//   }
+package digtest
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strings"
+
+	"go.uber.org/dig"
+)
+
+var _update = flag.Bool("digtest.update", false,
+	"updates the golden files of digtest.AssertGraphGolden if set")
+
+// TestingT is the subset of testing.TB used by digtest.
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+	FailNow()
+	Helper()
+}
+
+// Key identifies a value in a container. Where digtest accepts a key, a
+// pointer to the type of an unnamed value, such as new(*sql.DB), may be
+// used instead.
+type Key struct {
+	Type reflect.Type
+
+	// Only one of Name or Group may be set.
+	Name  string
+	Group string
+}
+
+// Named returns the Key for the value of type T with the given name, where
+// ptr is a pointer to T.
+func Named(ptr interface{}, name string) Key {
+	return Key{Type: reflect.TypeOf(ptr).Elem(), Name: name}
+}
+
+// Grouped returns the Key for the value group of type T with the given name,
+// where ptr is a pointer to T. The group is requested as a []T.
+func Grouped(ptr interface{}, group string) Key {
+	return Key{Type: reflect.TypeOf(ptr).Elem(), Group: group}
+}
+
+func (k Key) String() string {
+	if k.Name != "" {
+		return fmt.Sprintf("%v[name=%q]", k.Type, k.Name)
+	}
+	if k.Group != "" {
+		return fmt.Sprintf("%v[group=%q]", k.Type, k.Group)
+	}
+	return k.Type.String()
+}
+
+// keyOf converts a Key or a pointer to a type into a Key.
+func keyOf(i interface{}) (Key, error) {
+	if k, ok := i.(Key); ok {
+		if k.Type == nil {
+			return k, errors.New("key has no type")
+		}
+		return k, nil
+	}
+
+	t := reflect.TypeOf(i)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return Key{}, fmt.Errorf(
+			"expected a digtest.Key or a pointer to a type, got %v (type %v)", i, t)
+	}
+	return Key{Type: t.Elem()}, nil
+}
+
+var _errType = reflect.TypeOf((*error)(nil)).Elem()
+
+// requestFunc returns a function that may be passed to Invoke to request
+// the value identified by the given key. If nonEmpty is set, the function
+// fails if the requested value group is empty.
+func requestFunc(k Key, nonEmpty bool) interface{} {
+	field := reflect.StructField{Name: "Value", Type: k.Type}
+	switch {
+	case k.Group != "":
+		field.Type = reflect.SliceOf(k.Type)
+		field.Tag = reflect.StructTag(fmt.Sprintf(`group:"%v"`, k.Group))
+	case k.Name != "":
+		field.Tag = reflect.StructTag(fmt.Sprintf(`name:"%v"`, k.Name))
+	}
+
+	in := reflect.StructOf([]reflect.StructField{
+		{Name: "In", Type: reflect.TypeOf(dig.In{}), Anonymous: true},
+		field,
+	})
+	ftype := reflect.FuncOf([]reflect.Type{in}, []reflect.Type{_errType}, false /* variadic */)
+	return reflect.MakeFunc(ftype, func(args []reflect.Value) []reflect.Value {
+		err := reflect.Zero(_errType)
+		if nonEmpty && k.Group != "" && args[0].Field(1).Len() == 0 {
+			err = reflect.ValueOf(fmt.Errorf("value group %v is empty", k))
+		}
+		return []reflect.Value{err}
+	}).Interface()
+}
+
+// AssertResolvable asserts that the container is able to build the value
+// identified by the given key, which is either a Key or a pointer to the
+// type of an unnamed value.
+//
+//   digtest.AssertResolvable(t, c, new(*http.Server))
+//
+// No constructors are called. Only the presence of the dependencies of every
+// constructor that would be called is verified.
+func AssertResolvable(t TestingT, c *dig.Container, key interface{}) bool {
+	t.Helper()
+
+	k, err := keyOf(key)
+	if err != nil {
+		t.Errorf("invalid key: %v", err)
+		return false
+	}
+
+	dry := c.Clone(dig.CloneDryRun(true))
+	if err := dry.Invoke(requestFunc(k, false /* nonEmpty */)); err != nil {
+		t.Errorf("%v is not resolvable: %+v", k, err)
+		return false
+	}
+	return true
+}
+
+// AssertNoUnusedProviders asserts that every constructor provided to the
+// container is needed, directly or transitively, by at least one of the
+// given functions. The functions are typically the functions passed to
+// Invoke by the application.
+//
+//   digtest.AssertNoUnusedProviders(t, c, app.Run)
+func AssertNoUnusedProviders(t TestingT, c *dig.Container, roots ...interface{}) bool {
+	t.Helper()
+
+	report, err := c.Unused(roots...)
+	if err != nil {
+		t.Errorf("cannot find unused providers: %v", err)
+		return false
+	}
+	if len(report.Providers) > 0 {
+		locs := make([]string, len(report.Providers))
+		for i, p := range report.Providers {
+			locs[i] = p.Location.String()
+		}
+		t.Errorf("found %d unused providers:\n\t%v", len(locs), strings.Join(locs, "\n\t"))
+		return false
+	}
+	return true
+}
+
+// AssertGraphGolden asserts that the DOT graph of the container, as written
+// by dig.Visualize, matches the contents of the given file.
+//
+// Run the tests with the -digtest.update flag to write the current graph to
+// the file instead.
+//
+//   go test -run TestWiring -digtest.update
+func AssertGraphGolden(t TestingT, c *dig.Container, path string, opts ...dig.VisualizeOption) bool {
+	t.Helper()
+
+	var b bytes.Buffer
+	if err := dig.Visualize(c, &b, opts...); err != nil {
+		t.Errorf("cannot visualize container: %v", err)
+		return false
+	}
+
+	if *_update {
+		if err := ioutil.WriteFile(path, b.Bytes(), 0644); err != nil {
+			t.Errorf("cannot update golden file: %v", err)
+			return false
+		}
+		return true
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Errorf("cannot read golden file: %v", err)
+		return false
+	}
+
+	if got := b.String(); got != string(want) {
+		t.Errorf("graph does not match %v, run the tests with -digtest.update "+
+			"if the change is expected:\ngot:\n%v\nwant:\n%v", path, got, string(want))
+		return false
+	}
+	return true
+}
+
+// RequireProvides requires that the given constructor produces values for
+// all the given keys, each of which is either a Key or a pointer to the type
+// of an unnamed value. The test is stopped with FailNow otherwise.
+//
+//   digtest.RequireProvides(t, NewConnections, digtest.Named(new(*sql.DB), "ro"))
+//
+// The constructor is not called and its dependencies are not required.
+func RequireProvides(t TestingT, ctor interface{}, keys ...interface{}) {
+	t.Helper()
+
+	ctype := reflect.TypeOf(ctor)
+	if ctype == nil || ctype.Kind() != reflect.Func {
+		t.Errorf("expected a constructor function, got %v (type %v)", ctor, ctype)
+		t.FailNow()
+		return
+	}
+
+	// The stub has the same results as the constructor but no dependencies.
+	outs := make([]reflect.Type, ctype.NumOut())
+	for i := range outs {
+		outs[i] = ctype.Out(i)
+	}
+	stub := reflect.MakeFunc(
+		reflect.FuncOf(nil /* in */, outs, false /* variadic */),
+		func([]reflect.Value) []reflect.Value {
+			results := make([]reflect.Value, len(outs))
+			for i, t := range outs {
+				results[i] = reflect.Zero(t)
+			}
+			return results
+		},
+	)
+
+	// The stub does nothing so there's no need for a dry-run container. The
+	// function passed to Invoke must run to check that groups are not empty.
+	c := dig.New()
+	if err := c.Provide(stub.Interface()); err != nil {
+		t.Errorf("cannot provide constructor: %v", err)
+		t.FailNow()
+		return
+	}
+
+	failed := false
+	for _, key := range keys {
+		k, err := keyOf(key)
+		if err != nil {
+			t.Errorf("invalid key: %v", err)
+			failed = true
+			continue
+		}
+
+		if err := c.Invoke(requestFunc(k, true /* nonEmpty */)); err != nil {
+			t.Errorf("constructor does not provide %v: %v", k, err)
+			failed = true
+		}
+	}
+	if failed {
+		t.FailNow()
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package digtest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig"
+)
+
+// fakeT records failures instead of failing the test.
+type fakeT struct {
+	errors []string
+	failed bool
+}
+
+func (t *fakeT) Errorf(msg string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(msg, args...))
+}
+
+func (t *fakeT) FailNow() { t.failed = true }
+func (t *fakeT) Helper()  {}
+
+type (
+	config  struct{}
+	db      struct{}
+	server  struct{}
+	handler struct{}
+	unused  struct{}
+)
+
+type handlers struct {
+	dig.Out
+
+	H *handler `group:"handlers"`
+}
+
+type serverParams struct {
+	dig.In
+
+	DB       *db        `name:"ro"`
+	Handlers []*handler `group:"handlers"`
+}
+
+func newContainer(t *testing.T, called *bool) *dig.Container {
+	c := dig.New()
+	require.NoError(t, c.Provide(func() *config {
+		*called = true
+		return &config{}
+	}))
+	require.NoError(t, c.Provide(func(*config) *db {
+		*called = true
+		return &db{}
+	}, dig.Name("ro")))
+	require.NoError(t, c.Provide(func() handlers {
+		*called = true
+		return handlers{H: &handler{}}
+	}))
+	require.NoError(t, c.Provide(func(serverParams) *server {
+		*called = true
+		return &server{}
+	}))
+	return c
+}
+
+func TestAssertResolvable(t *testing.T) {
+	var called bool
+	c := newContainer(t, &called)
+
+	t.Run("resolvable", func(t *testing.T) {
+		ft := new(fakeT)
+		assert.True(t, AssertResolvable(ft, c, new(*server)))
+		assert.True(t, AssertResolvable(ft, c, Named(new(*db), "ro")))
+		assert.True(t, AssertResolvable(ft, c, Grouped(new(*handler), "handlers")))
+		assert.Empty(t, ft.errors)
+		assert.False(t, called, "constructors must not be called")
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		ft := new(fakeT)
+		assert.False(t, AssertResolvable(ft, c, new(*db)))
+		require.Len(t, ft.errors, 1)
+		assert.Contains(t, ft.errors[0], "*digtest.db is not resolvable")
+		assert.Contains(t, ft.errors[0], `- *digtest.db (did you mean to use *digtest.db[name="ro"]?)`)
+	})
+
+	t.Run("missing transitive dependency", func(t *testing.T) {
+		c := dig.New()
+		require.NoError(t, c.Provide(func(*config) *db { return &db{} }))
+
+		ft := new(fakeT)
+		assert.False(t, AssertResolvable(ft, c, new(*db)))
+		require.Len(t, ft.errors, 1)
+		assert.Contains(t, ft.errors[0], "- *digtest.config (did you mean to Provide it?)")
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		ft := new(fakeT)
+		assert.False(t, AssertResolvable(ft, c, db{}))
+		assert.False(t, AssertResolvable(ft, c, Key{}))
+		assert.Len(t, ft.errors, 2)
+	})
+}
+
+func TestAssertNoUnusedProviders(t *testing.T) {
+	var called bool
+	c := newContainer(t, &called)
+
+	ft := new(fakeT)
+	assert.True(t, AssertNoUnusedProviders(ft, c, func(*server) {}))
+	assert.Empty(t, ft.errors)
+
+	require.NoError(t, c.Provide(func() *unused { return &unused{} }))
+	assert.False(t, AssertNoUnusedProviders(ft, c, func(*server) {}))
+	require.Len(t, ft.errors, 1)
+	assert.Contains(t, ft.errors[0], "found 1 unused providers")
+	assert.Contains(t, ft.errors[0], "TestAssertNoUnusedProviders")
+
+	ft = new(fakeT)
+	assert.False(t, AssertNoUnusedProviders(ft, c, 42))
+	require.Len(t, ft.errors, 1)
+	assert.Contains(t, ft.errors[0], "root must be a function")
+	assert.False(t, called)
+}
+
+func TestAssertGraphGolden(t *testing.T) {
+	c := dig.New()
+	require.NoError(t, c.Provide(func() *config { return &config{} }))
+
+	ft := new(fakeT)
+	assert.True(t, AssertGraphGolden(ft, c, filepath.Join("testdata", "config.dot")))
+	assert.Empty(t, ft.errors)
+
+	require.NoError(t, c.Provide(func() *db { return &db{} }))
+	assert.False(t, AssertGraphGolden(ft, c, filepath.Join("testdata", "config.dot")))
+	require.Len(t, ft.errors, 1)
+	assert.Contains(t, ft.errors[0], "graph does not match")
+
+	t.Run("update", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "digtest")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		*_update = true
+		defer func() { *_update = false }()
+
+		path := filepath.Join(dir, "app.dot")
+		ft := new(fakeT)
+		assert.True(t, AssertGraphGolden(ft, c, path))
+		assert.Empty(t, ft.errors)
+		_, err = os.Stat(path)
+		assert.NoError(t, err)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		ft := new(fakeT)
+		assert.False(t, AssertGraphGolden(ft, c, filepath.Join("testdata", "missing.dot")))
+		require.Len(t, ft.errors, 1)
+		assert.Contains(t, ft.errors[0], "cannot read golden file")
+	})
+}
+
+func TestRequireProvides(t *testing.T) {
+	type results struct {
+		dig.Out
+
+		RO      *db      `name:"ro"`
+		Handler *handler `group:"handlers"`
+		Unnamed *config
+	}
+	ctor := func(*server) (results, error) {
+		panic("must not be called")
+	}
+
+	t.Run("provides", func(t *testing.T) {
+		ft := new(fakeT)
+		RequireProvides(ft, ctor,
+			new(*config),
+			Named(new(*db), "ro"),
+			Grouped(new(*handler), "handlers"),
+		)
+		assert.Empty(t, ft.errors)
+		assert.False(t, ft.failed)
+	})
+
+	t.Run("does not provide", func(t *testing.T) {
+		ft := new(fakeT)
+		RequireProvides(ft, ctor,
+			new(*db),
+			Named(new(*db), "rw"),
+			Grouped(new(*config), "configs"),
+		)
+		assert.True(t, ft.failed)
+		require.Len(t, ft.errors, 3)
+		assert.Contains(t, ft.errors[0], "constructor does not provide *digtest.db")
+		assert.Contains(t, ft.errors[1], `constructor does not provide *digtest.db[name="rw"]`)
+		assert.Contains(t, ft.errors[2], `value group *digtest.config[group="configs"] is empty`)
+	})
+
+	t.Run("invalid constructor", func(t *testing.T) {
+		ft := new(fakeT)
+		RequireProvides(ft, 42)
+		assert.True(t, ft.failed)
+
+		ft = new(fakeT)
+		RequireProvides(ft, func() {})
+		assert.True(t, ft.failed)
+		assert.Contains(t, ft.errors[0], "cannot provide constructor")
+	})
+}
@@ -284,6 +284,14 @@
 //     // ...
 //   }
 //
+// A Named Value may be made available under more than one name, for example
+// while a name is being migrated. Pass the dig.Names option to Provide, or
+// alias an existing value with Container.Alias. The constructor is still
+// called at most once and all names share the same value.
+//
+//   c.Provide(NewReadWriteConnection, dig.Names("rw", "primary"))
+//   c.Alias("master", "rw", new(*sql.DB))
+//
 // Value Groups
 //
 // Added in Dig 1.2.
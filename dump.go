@@ -0,0 +1,183 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/dig/internal/digreflect"
+)
+
+// previewLength is the maximum length, in runes, of the value previews
+// written by Container.Dump.
+const previewLength = 60
+
+// DumpOptions controls what Container.Dump writes.
+type DumpOptions struct {
+	// Locations adds the file and line where each constructor is defined.
+	Locations bool
+
+	// Values adds a section listing the values and value groups that the
+	// container already built.
+	Values bool
+
+	// Previews adds a short representation of each built value. It only
+	// applies with Values.
+	Previews bool
+
+	// Passives adds a section listing the types registered with
+	// PassiveProvide and the names they were resolved for.
+	Passives bool
+}
+
+// Dump writes a description of the container to w. Constructors are sorted
+// by package, name and results, and values by key, so that two containers
+// with the same constructors are always dumped the same way.
+//
+//   providers:
+//     "go.uber.org/foo".NewDB
+//       params: *foo.Config
+//       results: *foo.DB, *foo.DB[name="ro"]
+//   values:
+//     *foo.Config
+func (c *Container) Dump(w io.Writer, opts DumpOptions) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "providers:")
+	for _, n := range c.sortedNodes() {
+		info := c.providerInfo(n)
+		fmt.Fprintf(bw, "\t%q.%v", info.Location.Package, info.Location.Name)
+		if opts.Locations {
+			fmt.Fprintf(bw, " (%v:%v)", info.Location.File, info.Location.Line)
+		}
+		fmt.Fprintln(bw)
+		if len(info.Params) > 0 {
+			fmt.Fprintln(bw, "\t\tparams:", joinKeyInfos(info.Params))
+		}
+		fmt.Fprintln(bw, "\t\tresults:", joinKeyInfos(info.Results))
+	}
+
+	if opts.Values {
+		fmt.Fprintln(bw, "values:")
+		for _, k := range valueKeys(c.values) {
+			fmt.Fprint(bw, "\t", k)
+			if opts.Previews {
+				fmt.Fprint(bw, " = ", preview(c.values[k]))
+			}
+			fmt.Fprintln(bw)
+		}
+		for _, k := range groupKeys(c.groups) {
+			values := c.groups[k]
+			fmt.Fprintf(bw, "\t%v (%d values)", k, len(values))
+			if opts.Previews {
+				fmt.Fprint(bw, " = ", preview(values))
+			}
+			fmt.Fprintln(bw)
+		}
+	}
+
+	if opts.Passives {
+		fmt.Fprintln(bw, "passives:")
+		passives := make([]key, 0, len(c.passives))
+		for k := range c.passives {
+			passives = append(passives, k)
+		}
+		for _, k := range sortKeys(passives) {
+			loc := newLocation(digreflect.InspectFunc(c.passives[k].ctor))
+			fmt.Fprintf(bw, "\t%v from %q.%v", k.t, loc.Package, loc.Name)
+			if opts.Locations {
+				fmt.Fprintf(bw, " (%v:%v)", loc.File, loc.Line)
+			}
+			fmt.Fprintln(bw)
+			if resolved := c.passiveKeys(k.t); len(resolved) > 0 {
+				fmt.Fprintln(bw, "\t\tresolved:", joinKeys(resolved))
+			}
+		}
+	}
+
+	return bw.Flush()
+}
+
+// sortedNodes returns the nodes of the container sorted by location and
+// then by the keys of their results.
+func (c *Container) sortedNodes() []*node {
+	nodes := append([]*node(nil), c.nodes...)
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if d := compareLocations(nodes[i].location, nodes[j].location); d != 0 {
+			return d < 0
+		}
+		return joinKeys(resultKeys(nodes[i].resultList)) < joinKeys(resultKeys(nodes[j].resultList))
+	})
+	return nodes
+}
+
+// passiveKeys returns the sorted keys of type t built by constructors
+// generated by PassiveProvide.
+func (c *Container) passiveKeys(t reflect.Type) []key {
+	var keys []key
+	for _, n := range c.nodes {
+		if !n.passive {
+			continue
+		}
+		for _, k := range resultKeys(n.resultList) {
+			if k.t == t {
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+	return keys
+}
+
+func joinKeys(keys []key) string {
+	s := make([]string, len(keys))
+	for i, k := range keys {
+		s[i] = k.String()
+	}
+	return strings.Join(s, ", ")
+}
+
+func joinKeyInfos(infos []KeyInfo) string {
+	s := make([]string, len(infos))
+	for i, k := range infos {
+		s[i] = k.String()
+	}
+	return strings.Join(s, ", ")
+}
+
+// preview formats a value on a single line, truncated to previewLength.
+func preview(v interface{}) string {
+	s := strings.Join(strings.Fields(fmt.Sprintf("%+v", v)), " ")
+	if utf8.RuneCountInString(s) <= previewLength {
+		return s
+	}
+	// Cut on a rune boundary so that the preview remains valid UTF-8.
+	runes := []rune(s)
+	return string(runes[:previewLength-3]) + "..."
+}
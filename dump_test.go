@@ -0,0 +1,112 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDump(t *testing.T) {
+	type A struct{ Name string }
+	type B struct{}
+
+	newContainer := func(t *testing.T) *Container {
+		c := New()
+		require.NoError(t, c.Provide(func(*A) *B { return &B{} }))
+		require.NoError(t, c.Provide(func() *A { return &A{Name: "a"} }))
+		require.NoError(t, c.Provide(func() string { return "foo" }, Group("s")))
+		require.NoError(t, c.PassiveProvide(func(name string) *DB { return &DB{Name: name} }))
+		return c
+	}
+
+	t.Run("providers", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, newContainer(t).Dump(&buf, DumpOptions{}))
+		assert.Equal(t, `providers:
+	"go.uber.org/dig".TestDump.func1.1
+		params: *dig.A
+		results: *dig.B
+	"go.uber.org/dig".TestDump.func1.2
+		results: *dig.A
+	"go.uber.org/dig".TestDump.func1.3
+		results: string[group="s"]
+`, buf.String())
+	})
+
+	t.Run("is deterministic", func(t *testing.T) {
+		c := newContainer(t)
+		var first bytes.Buffer
+		require.NoError(t, c.Dump(&first, DumpOptions{Values: true, Passives: true}))
+		for i := 0; i < 10; i++ {
+			var buf bytes.Buffer
+			require.NoError(t, c.Dump(&buf, DumpOptions{Values: true, Passives: true}))
+			assert.Equal(t, first.String(), buf.String())
+		}
+	})
+
+	t.Run("values and passives", func(t *testing.T) {
+		c := newContainer(t)
+		type param struct {
+			In
+
+			B  *B
+			DB *DB      `name:"main"`
+			S  []string `group:"s"`
+		}
+		require.NoError(t, c.Invoke(func(param) {}))
+
+		var buf bytes.Buffer
+		require.NoError(t, c.Dump(&buf, DumpOptions{
+			Values:    true,
+			Previews:  true,
+			Passives:  true,
+			Locations: true,
+		}))
+		s := buf.String()
+		assert.Contains(t, s, `"go.uber.org/dig".TestDump.func1.1 (`)
+		assert.Contains(t, s, "dump_test.go:")
+		assert.Contains(t, s, "values:\n")
+		assert.Contains(t, s, "\t*dig.A = &{Name:a}\n")
+		assert.Contains(t, s, "\t*dig.B = &{}\n")
+		assert.Contains(t, s, "\tstring[group=\"s\"] (1 values) = [foo]\n")
+		assert.Contains(t, s, "passives:\n\t*dig.DB from \"go.uber.org/dig\".TestDump.func1.4 (")
+		assert.Contains(t, s, "\t\tresolved: *dig.DB[name=\"main\"]\n")
+	})
+}
+
+func TestPreview(t *testing.T) {
+	assert.Equal(t, "&{Name:a}", preview(&DB{Name: "a"}))
+	assert.Equal(t, "foo bar", preview("foo\n\tbar"))
+
+	long := preview(bytes.Repeat([]byte("x"), 100))
+	assert.Len(t, long, previewLength)
+	assert.True(t, len(long) > 3 && long[len(long)-3:] == "...")
+
+	runes := preview(strings.Repeat("é", 100))
+	assert.True(t, utf8.ValidString(runes), "previews must be cut on a rune boundary")
+	assert.Equal(t, previewLength, utf8.RuneCountInString(runes))
+}
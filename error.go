@@ -26,6 +26,7 @@ import (
 	"io"
 	"reflect"
 	"sort"
+	"strings"
 
 	"go.uber.org/dig/internal/digreflect"
 	"go.uber.org/dig/internal/dot"
@@ -38,6 +39,10 @@ import (
 // confused with the cause of the user-provided errors. For example, if we
 // used Unwrap(), then user-provided methods would also be unwrapped by
 // RootCause. We want RootCause to eliminate the dig error chain only.
+//
+// The dig errors that implement causer implement Unwrap as well, so that
+// errors.Is and errors.As see through the whole chain, user-provided errors
+// included.
 type causer interface {
 	fmt.Formatter
 
@@ -213,6 +218,8 @@ func (e wrappedError) cause() error {
 	return e.err
 }
 
+func (e wrappedError) Unwrap() error { return e.err }
+
 func (e wrappedError) writeMessage(w io.Writer, _ string) {
 	io.WriteString(w, e.msg)
 }
@@ -235,6 +242,8 @@ func (e errProvide) cause() error {
 	return e.Reason
 }
 
+func (e errProvide) Unwrap() error { return e.Reason }
+
 func (e errProvide) writeMessage(w io.Writer, verb string) {
 	fmt.Fprintf(w, "cannot provide function "+verb, e.Func)
 }
@@ -257,6 +266,8 @@ func (e errConstructorFailed) cause() error {
 	return e.Reason
 }
 
+func (e errConstructorFailed) Unwrap() error { return e.Reason }
+
 func (e errConstructorFailed) writeMessage(w io.Writer, verb string) {
 	fmt.Fprintf(w, "received non-nil error from function "+verb, e.Func)
 }
@@ -279,6 +290,8 @@ func (e errArgumentsFailed) cause() error {
 	return e.Reason
 }
 
+func (e errArgumentsFailed) Unwrap() error { return e.Reason }
+
 func (e errArgumentsFailed) writeMessage(w io.Writer, verb string) {
 	fmt.Fprintf(w, "could not build arguments for function "+verb, e.Func)
 }
@@ -301,6 +314,8 @@ func (e errMissingDependencies) cause() error {
 	return e.Reason
 }
 
+func (e errMissingDependencies) Unwrap() error { return e.Reason }
+
 func (e errMissingDependencies) writeMessage(w io.Writer, verb string) {
 	fmt.Fprintf(w, "missing dependencies for function "+verb, e.Func)
 }
@@ -323,6 +338,8 @@ func (e errParamSingleFailed) cause() error {
 	return e.Reason
 }
 
+func (e errParamSingleFailed) Unwrap() error { return e.Reason }
+
 func (e errParamSingleFailed) writeMessage(w io.Writer, _ string) {
 	fmt.Fprintf(w, "failed to build %v", e.Key)
 }
@@ -357,6 +374,8 @@ func (e errParamGroupFailed) cause() error {
 	return e.Reason
 }
 
+func (e errParamGroupFailed) Unwrap() error { return e.Reason }
+
 func (e errParamGroupFailed) writeMessage(w io.Writer, _ string) {
 	fmt.Fprintf(w, "could not build value group %v", e.Key)
 }
@@ -378,6 +397,10 @@ type missingType struct {
 	// If non-empty, we will include suggestions for what the user may have
 	// meant.
 	suggestions []key
+
+	// Explanations of why the value may be missing. These are included in
+	// the %+v form only.
+	hints []string
 }
 
 // Format prints a string representation of missingType.
@@ -428,6 +451,13 @@ func (mt missingType) Format(w fmt.State, v rune) {
 		}
 		io.WriteString(w, "?)")
 	}
+
+	if plusV {
+		for _, h := range mt.hints {
+			io.WriteString(w, "\n\t  ")
+			io.WriteString(w, h)
+		}
+	}
 }
 
 // errMissingType is returned when one or more values that were expected in
@@ -472,18 +502,123 @@ func newErrMissingTypes(c containerStore, k key) errMissingTypes {
 	mt := missingType{Key: k}
 	for _, t := range suggestions {
 		if len(c.getValueProviders(k.name, t)) > 0 {
-			k.t = t
-			mt.suggestions = append(mt.suggestions, k)
+			sug := k
+			sug.t = t
+			mt.suggestions = append(mt.suggestions, sug)
+		}
+	}
+
+	for _, known := range c.knownKeys() {
+		switch {
+		case known.t != k.t:
+			if sameNameOtherPackage(known.t, k.t) {
+				mt.hints = append(mt.hints, fmt.Sprintf(
+					"%v from package %q is provided, which is a different type",
+					known, indirect(known.t).PkgPath()))
+			}
+		case known.group != "":
+			mt.hints = append(mt.hints, fmt.Sprintf(
+				"%v is provided to a value group, use a []%v with the `group:%q` tag to request it",
+				known, known.t, known.group))
+		case known.name == k.name:
+			// Only possible for aliases of missing values.
+		case known.name == "", k.name == "", similarNames(known.name, k.name):
+			// Maybe the value is provided without a name, with a name, or
+			// with a typo in its name.
+			mt.suggestions = append(mt.suggestions, known)
 		}
 	}
 
+	if c.hasPassiveProvider(k.t) {
+		mt.hints = append(mt.hints, fmt.Sprintf(
+			"the PassiveProvide fallback for %v only applies to the parameters of invoked functions",
+			k.t))
+	}
+
 	return errMissingTypes{mt}
 }
 
+// sameNameOtherPackage reports whether the two types have the same name but
+// are declared in different packages, like vendored copies of a package or
+// different major versions of a module.
+func sameNameOtherPackage(a, b reflect.Type) bool {
+	for a.Kind() == reflect.Ptr && b.Kind() == reflect.Ptr {
+		a, b = a.Elem(), b.Elem()
+	}
+	return a.Name() != "" && a.Name() == b.Name() && a.PkgPath() != b.PkgPath()
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// similarNames reports whether b is likely a typo of a, based on the edit
+// distance between them.
+func similarNames(a, b string) bool {
+	max := len(a)
+	if len(b) > max {
+		max = len(b)
+	}
+	threshold := max / 3
+	if threshold < 1 {
+		threshold = 1
+	}
+	return editDistance(a, b) <= threshold
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func minInt(first int, rest ...int) int {
+	m := first
+	for _, i := range rest {
+		if i < m {
+			m = i
+		}
+	}
+	return m
+}
+
 func (e errMissingTypes) Error() string {
 	return fmt.Sprint(e)
 }
 
+// As sets target to the MissingTypeError for these missing types if target
+// is a *MissingTypeError.
+func (e errMissingTypes) As(target interface{}) bool {
+	mte, ok := target.(*MissingTypeError)
+	if !ok {
+		return false
+	}
+
+	mte.Keys = make([]KeyInfo, len(e))
+	for i, mt := range e {
+		mte.Keys[i] = mt.Key.info()
+	}
+	return true
+}
+
 func (e errMissingTypes) Format(w fmt.State, v rune) {
 	multiline := w.Flag('+') && v == 'v'
 
@@ -529,6 +664,38 @@ func (e errMissingTypes) updateGraph(g *dot.Graph) {
 	g.AddMissingNodes(missing)
 }
 
+// MissingTypeError is the error returned when the container is unable to
+// find a value that a function depends on. Use errors.As to retrieve it from
+// an error returned by Invoke.
+//
+//   var mte dig.MissingTypeError
+//   if errors.As(err, &mte) {
+//     for _, k := range mte.Keys {
+//       log.Printf("not provided: %v", k)
+//     }
+//   }
+type MissingTypeError struct {
+	// Keys of the values that are missing.
+	Keys []KeyInfo
+}
+
+func (e MissingTypeError) Error() string {
+	keys := make([]string, len(e.Keys))
+	for i, k := range e.Keys {
+		keys[i] = k.String()
+	}
+	if len(keys) == 1 {
+		return "missing type: " + keys[0]
+	}
+	return "missing types: " + strings.Join(keys, "; ")
+}
+
+// IsMissingType reports whether the given error, or any error it wraps, was
+// caused by values missing from the container.
+func IsMissingType(err error) bool {
+	return errors.As(err, new(MissingTypeError))
+}
+
 type errVisualizer interface {
 	updateGraph(*dot.Graph)
 }
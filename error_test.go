@@ -23,12 +23,14 @@ package dig
 import (
 	"errors"
 	"fmt"
+	htmltemplate "html/template"
 	"io"
 	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
 	"testing"
+	texttemplate "text/template"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -337,6 +339,22 @@ func TestMissingTypeFormatting(t *testing.T) {
 			wantV:     "dig.type1 (did you mean *dig.type1, or dig.someInterface?)",
 			wantPlusV: "dig.type1 (did you mean to use one of *dig.type1, or dig.someInterface?)",
 		},
+		{
+			desc: "hints",
+			give: missingType{
+				Key: key{t: reflect.TypeOf(type1{})},
+				hints: []string{
+					"first hint",
+					"second hint",
+				},
+			},
+			wantV: "dig.type1",
+			wantPlusV: joinLines(
+				"dig.type1 (did you mean to Provide it?)",
+				"	  first hint",
+				"	  second hint",
+			),
+		},
 	}
 
 	for _, tt := range tests {
@@ -517,3 +535,203 @@ func TestErrorFormatting(t *testing.T) {
 		})
 	}
 }
+
+type customErr struct{ code int }
+
+func (e *customErr) Error() string { return fmt.Sprintf("custom error %d", e.code) }
+
+func TestErrorUnwrap(t *testing.T) {
+	sentinel := errors.New("sentinel")
+	custom := &customErr{code: 42}
+	someFunc := &digreflect.Func{Package: "foo", Name: "Bar"}
+
+	wrappers := []struct {
+		desc string
+		wrap func(error) error
+	}{
+		{"wrappedError", func(err error) error { return errf("something went wrong", err) }},
+		{"errProvide", func(err error) error { return errProvide{Func: someFunc, Reason: err} }},
+		{"errConstructorFailed", func(err error) error { return errConstructorFailed{Func: someFunc, Reason: err} }},
+		{"errArgumentsFailed", func(err error) error { return errArgumentsFailed{Func: someFunc, Reason: err} }},
+		{"errMissingDependencies", func(err error) error { return errMissingDependencies{Func: someFunc, Reason: err} }},
+		{"errParamSingleFailed", func(err error) error { return errParamSingleFailed{Key: key{t: _errType}, Reason: err} }},
+		{"errParamGroupFailed", func(err error) error { return errParamGroupFailed{Key: key{t: _errType}, Reason: err} }},
+	}
+
+	for _, w := range wrappers {
+		t.Run(w.desc, func(t *testing.T) {
+			err := w.wrap(fmt.Errorf("wrapped: %w", sentinel))
+			assert.True(t, errors.Is(err, sentinel))
+
+			err = w.wrap(errArgumentsFailed{Func: someFunc, Reason: custom})
+			var got *customErr
+			require.True(t, errors.As(err, &got))
+			assert.Equal(t, custom, got)
+		})
+	}
+}
+
+func TestMissingTypeError(t *testing.T) {
+	type type1 struct{}
+	type type2 struct{}
+
+	t.Run("Invoke", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func(*type1) *type2 { return &type2{} }))
+
+		type params struct {
+			In
+
+			T2 *type2
+			T1 *type1 `name:"foo"`
+		}
+		err := c.Invoke(func(params) {})
+		require.Error(t, err)
+		assert.True(t, IsMissingType(err))
+
+		var mte MissingTypeError
+		require.True(t, errors.As(err, &mte))
+		assert.Equal(t, []KeyInfo{
+			{Type: reflect.TypeOf(&type1{}), Name: "foo"},
+		}, mte.Keys)
+		assert.Equal(t, `missing type: *dig.type1[name="foo"]`, mte.Error())
+	})
+
+	t.Run("transitive", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func(string, int) *type1 { return &type1{} }))
+		require.NoError(t, c.Provide(func(*type1) *type2 { return &type2{} }))
+
+		err := c.Invoke(func(*type2) {})
+		var mte MissingTypeError
+		require.True(t, errors.As(err, &mte))
+		assert.Equal(t, []KeyInfo{
+			{Type: reflect.TypeOf("")},
+			{Type: reflect.TypeOf(0)},
+		}, mte.Keys)
+		assert.Equal(t, "missing types: string; int", mte.Error())
+	})
+
+	t.Run("other errors", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() (*type1, error) {
+			return nil, errors.New("great sadness")
+		}))
+		assert.False(t, IsMissingType(c.Invoke(func(*type1) {})))
+		assert.False(t, IsMissingType(nil))
+	})
+}
+
+func TestMissingTypeSuggestions(t *testing.T) {
+	type type1 struct{}
+
+	type1Type := reflect.TypeOf(&type1{})
+	missing := func(t *testing.T, c *Container, k key) missingType {
+		mts := newErrMissingTypes(c, k)
+		require.Len(t, mts, 1)
+		return mts[0]
+	}
+
+	t.Run("similar names", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() *type1 { return nil }, Name("readonly")))
+		require.NoError(t, c.Provide(func() *type1 { return nil }, Name("readwrite")))
+		require.NoError(t, c.Provide(func() *type1 { return nil }, Name("other")))
+		require.NoError(t, c.Alias("read-only", "readonly", new(*type1)))
+
+		mt := missing(t, c, key{t: type1Type, name: "read_only"})
+		assert.Equal(t, []key{
+			{t: type1Type, name: "read-only"},
+			{t: type1Type, name: "readonly"},
+		}, mt.suggestions)
+		assert.Equal(t,
+			`*dig.type1[name="read_only"] (did you mean to use one of *dig.type1[name="read-only"], or *dig.type1[name="readonly"]?)`,
+			fmt.Sprintf("%+v", mt))
+	})
+
+	t.Run("unnamed only", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() *type1 { return nil }))
+
+		mt := missing(t, c, key{t: type1Type, name: "foo"})
+		assert.Equal(t, []key{{t: type1Type}}, mt.suggestions)
+	})
+
+	t.Run("named only", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() *type1 { return nil }, Name("foo")))
+
+		mt := missing(t, c, key{t: type1Type})
+		assert.Equal(t, []key{{t: type1Type, name: "foo"}}, mt.suggestions)
+	})
+
+	t.Run("group only", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() *type1 { return nil }, Group("ones")))
+
+		mt := missing(t, c, key{t: type1Type})
+		assert.Empty(t, mt.suggestions)
+		assert.Equal(t, joinLines(
+			"*dig.type1 (did you mean to Provide it?)",
+			"	  *dig.type1[group=\"ones\"] is provided to a value group, "+
+				"use a []*dig.type1 with the `group:\"ones\"` tag to request it",
+		), fmt.Sprintf("%+v", mt))
+		assert.Equal(t, "*dig.type1", fmt.Sprint(mt))
+	})
+
+	t.Run("same name in another package", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() *htmltemplate.Template { return nil }))
+
+		mt := missing(t, c, key{t: reflect.TypeOf(&texttemplate.Template{})})
+		assert.Equal(t, []string{
+			`*template.Template from package "html/template" is provided, which is a different type`,
+		}, mt.hints)
+	})
+
+	t.Run("bypassed passive provider", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.PassiveProvide(func(string) (*type1, error) { return nil, nil }))
+
+		mt := missing(t, c, key{t: type1Type, name: "foo"})
+		assert.Equal(t, []string{
+			"the PassiveProvide fallback for *dig.type1 only applies to the parameters of invoked functions",
+		}, mt.hints)
+
+		type type2 struct{}
+		require.NoError(t, c.Provide(func(p struct {
+			In
+
+			T1 *type1 `name:"foo"`
+		}) *type2 {
+			return nil
+		}))
+		err := c.Invoke(func(*type2) {})
+		assert.Contains(t, fmt.Sprintf("%+v", err), "the PassiveProvide fallback for *dig.type1")
+		assert.NotContains(t, err.Error(), "PassiveProvide")
+	})
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		want    int
+		similar bool
+	}{
+		{"", "", 0, true},
+		{"a", "", 1, true},
+		{"ro", "rw", 1, true},
+		{"db", "dbs", 1, true},
+		{"kitten", "sitting", 3, false},
+		{"readonly", "read_only", 1, true},
+		{"foo", "bar", 3, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.want, editDistance(tt.a, tt.b))
+			assert.Equal(t, tt.want, editDistance(tt.b, tt.a))
+			assert.Equal(t, tt.similar, similarNames(tt.a, tt.b))
+		})
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"reflect"
+	"time"
+)
+
+// EventHandler receives the events emitted by a container. Events are
+// delivered synchronously, from the goroutine calling the container.
+type EventHandler interface {
+	HandleEvent(Event)
+}
+
+// EventHandlerFunc is an EventHandler implemented by a function.
+type EventHandlerFunc func(Event)
+
+// HandleEvent calls f(e).
+func (f EventHandlerFunc) HandleEvent(e Event) { f(e) }
+
+// WithEventHandler is an Option that delivers the events emitted by the
+// container to h, for example to log them or record metrics and traces.
+//
+//   c := dig.New(dig.WithEventHandler(dig.EventHandlerFunc(func(e dig.Event) {
+//     if e, ok := e.(*dig.ConstructorDone); ok {
+//       log.Printf("%v took %v", e.Func, e.Duration)
+//     }
+//   })))
+//
+// The option may be given several times to add several handlers.
+func WithEventHandler(h EventHandler) Option {
+	return optionFunc(func(c *Container) {
+		c.eventHandlers = append(c.eventHandlers, h)
+	})
+}
+
+// Event is an event emitted by a container. It is one of the pointer types
+// of this file, such as *ProvideStart or *ConstructorDone.
+type Event interface {
+	event()
+}
+
+// ProvideStart is emitted when a constructor is passed to Provide.
+type ProvideStart struct {
+	Func Location
+}
+
+// ProvideDone is emitted when Provide returns.
+type ProvideDone struct {
+	Func Location
+
+	// Err is the error returned by Provide, if any.
+	Err error
+}
+
+// InvokeStart is emitted when a function is passed to Invoke.
+type InvokeStart struct {
+	Func Location
+}
+
+// InvokeDone is emitted when Invoke returns.
+type InvokeDone struct {
+	Func Location
+
+	// Err is the error returned by Invoke, if any.
+	Err error
+}
+
+// ConstructorStart is emitted before a constructor is called, once its
+// dependencies are built.
+type ConstructorStart struct {
+	Func Location
+
+	// Results are the values the constructor produces.
+	Results []KeyInfo
+}
+
+// ConstructorDone is emitted after a constructor was called.
+type ConstructorDone struct {
+	Func Location
+
+	// Results are the values the constructor produces.
+	Results []KeyInfo
+
+	// Duration is the time spent in the constructor, not including its
+	// dependencies.
+	Duration time.Duration
+
+	// Err is the error returned by the constructor, if any.
+	Err error
+}
+
+// PassiveResolved is emitted when a function passed to PassiveProvide is
+// used to provide a value of the given type and name.
+type PassiveResolved struct {
+	Name string
+	Type reflect.Type
+}
+
+// FieldInjected is emitted when a field tagged with inject is populated.
+type FieldInjected struct {
+	// Struct is the type of the struct the field belongs to.
+	Struct reflect.Type
+
+	// Field is the name of the field.
+	Field string
+
+	// Key identifies the value the field was populated with.
+	Key KeyInfo
+}
+
+func (*ProvideStart) event()     {}
+func (*ProvideDone) event()      {}
+func (*InvokeStart) event()      {}
+func (*InvokeDone) event()       {}
+func (*ConstructorStart) event() {}
+func (*ConstructorDone) event()  {}
+func (*PassiveResolved) event()  {}
+func (*FieldInjected) event()    {}
+
+func (c *Container) handlesEvents() bool {
+	return len(c.eventHandlers) > 0
+}
+
+// emit delivers an event to the event handlers of the container.
+func (c *Container) emit(e Event) {
+	for _, h := range c.eventHandlers {
+		h.HandleEvent(e)
+	}
+}
+
+// resultInfos returns the keys of the values produced by a node.
+func resultInfos(n *node) []KeyInfo {
+	return keyInfos(resultKeys(n.resultList))
+}
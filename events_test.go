@@ -0,0 +1,132 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventHandler(t *testing.T) {
+	type A struct{}
+	type B struct {
+		A *A `inject:""`
+	}
+
+	var events []string
+	record := EventHandlerFunc(func(e Event) {
+		switch e := e.(type) {
+		case *ProvideStart:
+			events = append(events, "provide start "+e.Func.Name)
+		case *ProvideDone:
+			events = append(events, fmt.Sprintf("provide done %v %v", e.Func.Name, e.Err != nil))
+		case *InvokeStart:
+			events = append(events, "invoke start "+e.Func.Name)
+		case *InvokeDone:
+			events = append(events, fmt.Sprintf("invoke done %v %v", e.Func.Name, e.Err))
+		case *ConstructorStart:
+			events = append(events, fmt.Sprintf("constructor start %v %v", e.Func.Name, e.Results))
+		case *ConstructorDone:
+			events = append(events, fmt.Sprintf("constructor done %v %v %v", e.Func.Name, e.Results, e.Err))
+			assert.True(t, e.Duration >= 0)
+		case *PassiveResolved:
+			events = append(events, fmt.Sprintf("passive %v %v", e.Name, e.Type))
+		case *FieldInjected:
+			events = append(events, fmt.Sprintf("injected %v.%v %v", e.Struct.Name(), e.Field, e.Key))
+		default:
+			t.Fatalf("unexpected event %T", e)
+		}
+	})
+
+	t.Run("provide and invoke", func(t *testing.T) {
+		events = nil
+		c := New(WithEventHandler(record))
+		require.NoError(t, c.Provide(func() *A { return &A{} }))
+		require.NoError(t, c.Provide(func() *B { return &B{} }))
+		require.Error(t, c.Provide(func() *A { return &A{} }))
+		require.NoError(t, c.Invoke(func(*B) {}))
+
+		assert.Equal(t, []string{
+			"provide start TestEventHandler.func2.1",
+			"provide done TestEventHandler.func2.1 false",
+			"provide start TestEventHandler.func2.2",
+			"provide done TestEventHandler.func2.2 false",
+			"provide start TestEventHandler.func2.3",
+			"provide done TestEventHandler.func2.3 true",
+			"invoke start TestEventHandler.func2.4",
+			"constructor start TestEventHandler.func2.2 [*dig.B]",
+			"constructor done TestEventHandler.func2.2 [*dig.B] <nil>",
+			"constructor start TestEventHandler.func2.1 [*dig.A]",
+			"constructor done TestEventHandler.func2.1 [*dig.A] <nil>",
+			"injected B.A *dig.A",
+			"invoke done TestEventHandler.func2.4 <nil>",
+		}, events)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		events = nil
+		c := New(WithEventHandler(record))
+		require.NoError(t, c.Provide(func() (*A, error) { return nil, errors.New("great sadness") }))
+		require.Error(t, c.Invoke(func(*A) {}))
+
+		require.Len(t, events, 6)
+		assert.Equal(t, "constructor done TestEventHandler.func3.1 [*dig.A] great sadness", events[4])
+		assert.Contains(t, events[5], "invoke done TestEventHandler.func3.2 could not build arguments")
+	})
+
+	t.Run("passive", func(t *testing.T) {
+		c := New(WithEventHandler(record))
+		require.NoError(t, c.PassiveProvide(func(name string) *DB { return &DB{Name: name} }))
+
+		events = nil
+		require.NoError(t, c.Invoke(func(struct {
+			In
+
+			DB *DB `name:"main"`
+		}) {
+		}))
+		assert.Contains(t, events, "passive main *dig.DB")
+		for _, e := range events {
+			assert.NotContains(t, e, "provide", "the name given to passive constructors is not provided by the user")
+		}
+	})
+
+	t.Run("several handlers and clone", func(t *testing.T) {
+		var count int
+		counter := EventHandlerFunc(func(Event) { count++ })
+		c := New(WithEventHandler(counter), WithEventHandler(counter))
+		require.NoError(t, c.Provide(func() *A { return &A{} }))
+		assert.Equal(t, 4, count)
+
+		require.NoError(t, c.Clone().Invoke(func(*A) {}))
+		assert.Equal(t, 12, count)
+	})
+
+	t.Run("no handlers", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() *A { return &A{} }))
+		require.NoError(t, c.Invoke(func(*A) {}))
+	})
+}
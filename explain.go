@@ -0,0 +1,208 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Explanation describes why a value is, or would be, built by a container:
+// the chains of functions which need the value, starting with the
+// functions passed to Invoke.
+type Explanation struct {
+	// Key identifies the explained value.
+	Key KeyInfo
+
+	// Built is set if the container already built the value.
+	Built bool
+
+	// Roots has a step for every function passed to Invoke which needs
+	// the value, directly or through the values it depends on.
+	Roots []*ExplainStep
+}
+
+// ExplainStep is a function which needs a value on the way to the explained
+// value.
+type ExplainStep struct {
+	// Func is the function passed to Invoke or the constructor.
+	Func Location
+
+	// Key is the value requested by Func.
+	Key KeyInfo
+
+	// Next has the steps of the constructors of Key which need the
+	// explained value. It is empty if Key is the explained value.
+	Next []*ExplainStep
+}
+
+// String prints the explanation as an indented tree.
+//
+//   *sql.DB is built because:
+//   	"main".run (main.go:12) needs *http.Server
+//   		"main".NewServer (server.go:30) needs *sql.DB
+func (e *Explanation) String() string {
+	var b bytes.Buffer
+	switch {
+	case len(e.Roots) == 0:
+		fmt.Fprintf(&b, "%v is not needed by any function passed to Invoke", e.Key)
+	case e.Built:
+		fmt.Fprintf(&b, "%v is built because:", e.Key)
+	default:
+		fmt.Fprintf(&b, "%v is not built but is needed by:", e.Key)
+	}
+	writeSteps(&b, e.Roots, 1)
+	return b.String()
+}
+
+func writeSteps(b *bytes.Buffer, steps []*ExplainStep, depth int) {
+	for _, s := range steps {
+		fmt.Fprintf(b, "\n%v%v needs %v", strings.Repeat("\t", depth), s.Func, s.Key)
+		writeSteps(b, s.Next, depth+1)
+	}
+}
+
+// Explain explains why the container builds the value of the type pointed to
+// by ptr, or would build it: it returns the chains of constructors from the
+// functions passed to Invoke so far down to the value. Functions for which
+// Invoke failed are not included, and a function invoked several times is
+// included once.
+//
+//   exp, err := c.Explain(new(*sql.DB))
+//   fmt.Println(exp)
+//
+// Every constructor which may provide a value along the way is considered,
+// whether it was called or not. Use WhyBuilt to only consider the
+// constructors that were called.
+func (c *Container) Explain(ptr interface{}) (*Explanation, error) {
+	t := reflect.TypeOf(ptr)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return nil, errf("expected a pointer to a type, got %v (type %v)", ptr, t)
+	}
+	return c.explain(key{t: t.Elem()}, false /* builtOnly */)
+}
+
+// WhyBuilt explains why the container built the value identified by the
+// given key. Unlike Explain, only the constructors which were called are
+// considered, so the chains show which functions passed to Invoke actually
+// caused the value to be built.
+func (c *Container) WhyBuilt(k KeyInfo) (*Explanation, error) {
+	if k.Type == nil {
+		return nil, errf("can't explain a key without a type")
+	}
+	if k.Name != "" && k.Group != "" {
+		return nil, errf("can't explain a key with both a name and a group")
+	}
+	return c.explain(key{t: k.Type, name: k.Name, group: k.Group}, true /* builtOnly */)
+}
+
+func (c *Container) explain(k key, builtOnly bool) (*Explanation, error) {
+	if k.group == "" {
+		k = c.resolveAlias(k)
+	}
+	providers := c.providers[k]
+	if len(providers) == 0 {
+		return nil, newErrMissingTypes(c, k)
+	}
+
+	e := &Explanation{Key: k.info()}
+	if k.group == "" {
+		_, e.Built = c.values[k]
+	} else {
+		for _, n := range providers {
+			e.Built = e.Built || n.called
+		}
+	}
+
+	x := explainer{
+		c:         c,
+		target:    k,
+		builtOnly: builtOnly,
+		steps:     make(map[*node][]*ExplainStep),
+	}
+	for _, inv := range c.invoked {
+		e.Roots = append(e.Roots, x.explainParams(newLocation(inv.location), inv.paramList)...)
+	}
+	return e, nil
+}
+
+// explainer finds the chains of constructors leading to a value.
+type explainer struct {
+	c         *Container
+	target    key
+	builtOnly bool
+
+	// Steps of the nodes already explained. A nil entry marks a node being
+	// explained, which is skipped to avoid following cycles.
+	steps map[*node][]*ExplainStep
+}
+
+// explainParams returns the steps for the values requested by a function
+// which lead to the target.
+func (x *explainer) explainParams(loc Location, pl paramList) []*ExplainStep {
+	var steps []*ExplainStep
+	for _, k := range requestedKeys(x.c, pl) {
+		s := &ExplainStep{Func: loc, Key: k.info()}
+		if k != x.target {
+			for _, n := range x.c.providers[k] {
+				s.Next = append(s.Next, x.explainNode(n)...)
+			}
+			if len(s.Next) == 0 {
+				continue
+			}
+		}
+		steps = append(steps, s)
+	}
+	return steps
+}
+
+func (x *explainer) explainNode(n *node) []*ExplainStep {
+	if x.builtOnly && !n.called {
+		return nil
+	}
+	if steps, ok := x.steps[n]; ok {
+		return steps
+	}
+	x.steps[n] = nil
+	steps := x.explainParams(newLocation(n.location), n.paramList)
+	x.steps[n] = steps
+	return steps
+}
+
+// requestedKeys returns the keys of the values requested by a param list,
+// including values requested through inject tags, with aliases resolved and
+// without duplicates.
+func requestedKeys(c containerStore, pl paramList) []key {
+	var keys []key
+	seen := make(map[key]struct{})
+	for _, k := range paramKeys(pl) {
+		if k.group == "" {
+			k = c.resolveAlias(k)
+		}
+		if _, ok := seen[k]; !ok {
+			seen[k] = struct{}{}
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,228 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplain(t *testing.T) {
+	type A struct{}
+	type B struct{}
+	type C struct{}
+	type D struct{}
+
+	type params struct {
+		In
+
+		B *B
+		C *C `name:"c"`
+	}
+
+	newContainer := func(t *testing.T) *Container {
+		c := New()
+		require.NoError(t, c.Provide(func() *A { return &A{} }))
+		require.NoError(t, c.Provide(func(*A) *B { return &B{} }))
+		require.NoError(t, c.Provide(func(*A) *C { return &C{} }, Names("c", "alias")))
+		require.NoError(t, c.Provide(func(*A) *D { return &D{} }))
+		return c
+	}
+
+	// funcs returns the names of the functions of the steps, as a tree.
+	var funcs func([]*ExplainStep) []interface{}
+	funcs = func(steps []*ExplainStep) []interface{} {
+		var names []interface{}
+		for _, s := range steps {
+			names = append(names, s.Key.String())
+			if len(s.Next) > 0 {
+				names = append(names, funcs(s.Next))
+			}
+		}
+		return names
+	}
+
+	t.Run("chains from invoked functions", func(t *testing.T) {
+		c := newContainer(t)
+		require.NoError(t, c.Invoke(func(params) {}))
+		require.NoError(t, c.Invoke(func(*B, *D) {}))
+
+		e, err := c.Explain(new(*A))
+		require.NoError(t, err)
+		assert.True(t, e.Built)
+		assert.Equal(t, reflect.TypeOf(&A{}), e.Key.Type)
+		assert.Equal(t, []interface{}{
+			"*dig.B", []interface{}{"*dig.A"},
+			`*dig.C[name="c"]`, []interface{}{"*dig.A"},
+			"*dig.B", []interface{}{"*dig.A"},
+			"*dig.D", []interface{}{"*dig.A"},
+		}, funcs(e.Roots))
+		assert.Equal(t, "TestExplain.func3.1", e.Roots[0].Func.Name)
+		assert.Equal(t, "go.uber.org/dig", e.Roots[0].Func.Package)
+	})
+
+	t.Run("direct dependency", func(t *testing.T) {
+		c := newContainer(t)
+		require.NoError(t, c.Invoke(func(*B) {}))
+
+		e, err := c.Explain(new(*B))
+		require.NoError(t, err)
+		require.Len(t, e.Roots, 1)
+		assert.Empty(t, e.Roots[0].Next)
+	})
+
+	t.Run("WhyBuilt only follows called constructors", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() *A { return &A{} }))
+		require.NoError(t, c.Provide(func(*A) *B { return &B{} }))
+		require.NoError(t, c.Provide(func(*A) *D { return &D{} }))
+		require.NoError(t, c.Invoke(func(*B) {}))
+		require.NoError(t, c.Invoke(func(*D) {}))
+		require.NoError(t, c.Replace(func(*A) *D { return &D{} }, Force()))
+
+		e, err := c.WhyBuilt(KeyInfo{Type: reflect.TypeOf(&A{})})
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{"*dig.B", []interface{}{"*dig.A"}}, funcs(e.Roots))
+
+		e, err = c.Explain(new(*A))
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{
+			"*dig.B", []interface{}{"*dig.A"},
+			"*dig.D", []interface{}{"*dig.A"},
+		}, funcs(e.Roots))
+
+		e, err = c.Explain(new(*D))
+		require.NoError(t, err)
+		assert.False(t, e.Built)
+		assert.Equal(t, []interface{}{"*dig.D"}, funcs(e.Roots))
+		assert.Contains(t, e.String(), "*dig.D is not built but is needed by:")
+
+		e, err = c.WhyBuilt(KeyInfo{Type: reflect.TypeOf(&D{})})
+		require.NoError(t, err)
+		assert.Len(t, e.Roots, 1, "invoked functions are always followed")
+	})
+
+	t.Run("invoked functions are recorded once if successful", func(t *testing.T) {
+		type E struct{}
+
+		c := newContainer(t)
+		require.NoError(t, c.Provide(func(*E) *D { return &D{} }, Name("d")))
+		s := c.Snapshot()
+		for i := 0; i < 3; i++ {
+			require.NoError(t, c.Invoke(func(*B) {}))
+		}
+		require.Error(t, c.Invoke(func(p struct {
+			In
+
+			D *D `name:"d"`
+		}) {
+		}), "*E is missing")
+		require.Error(t, c.Invoke(func(*B) error { return errors.New("great sadness") }))
+
+		e, err := c.Explain(new(*A))
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{"*dig.B", []interface{}{"*dig.A"}}, funcs(e.Roots))
+		assert.Len(t, c.invoked, 1)
+
+		require.NoError(t, c.Restore(s))
+		assert.Empty(t, c.invoked, "Restore must forget functions invoked since the snapshot")
+	})
+
+	t.Run("aliases", func(t *testing.T) {
+		c := newContainer(t)
+		require.NoError(t, c.Invoke(func(p struct {
+			In
+
+			C *C `name:"alias"`
+		}) {
+		}))
+
+		e, err := c.WhyBuilt(KeyInfo{Type: reflect.TypeOf(&C{}), Name: "alias"})
+		require.NoError(t, err)
+		assert.Equal(t, "c", e.Key.Name, "aliases must be resolved")
+		require.Len(t, e.Roots, 1)
+	})
+
+	t.Run("groups", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() *A { return &A{} }, Group("as")))
+		require.NoError(t, c.Invoke(func(p struct {
+			In
+
+			As []*A `group:"as"`
+		}) {
+		}))
+
+		e, err := c.WhyBuilt(KeyInfo{Type: reflect.TypeOf(&A{}), Group: "as"})
+		require.NoError(t, err)
+		assert.True(t, e.Built)
+		assert.Equal(t, []interface{}{`*dig.A[group="as"]`}, funcs(e.Roots))
+	})
+
+	t.Run("String", func(t *testing.T) {
+		c := newContainer(t)
+		require.NoError(t, c.Invoke(func(*B) {}))
+
+		e, err := c.Explain(new(*A))
+		require.NoError(t, err)
+		assert.Regexp(t, `^\*dig.A is built because:
+	"go.uber.org/dig".TestExplain.func\d+.1 \(\S+explain_test.go:\d+\) needs \*dig.B
+		"go.uber.org/dig".TestExplain.func[\d.]+ \(\S+explain_test.go:\d+\) needs \*dig.A$`, e.String())
+
+		e, err = c.Explain(new(*D))
+		require.NoError(t, err)
+		assert.Equal(t, "*dig.D is not needed by any function passed to Invoke", e.String())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		c := newContainer(t)
+
+		_, err := c.Explain(A{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "expected a pointer to a type")
+
+		_, err = c.Explain(new(string))
+		require.Error(t, err)
+		assert.True(t, IsMissingType(err))
+
+		_, err = c.WhyBuilt(KeyInfo{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "without a type")
+	})
+
+	t.Run("functions built with reflect.MakeFunc", func(t *testing.T) {
+		c := newContainer(t)
+		for _, v := range []interface{}{new(*A), new(*B)} {
+			ft := reflect.FuncOf([]reflect.Type{reflect.TypeOf(v).Elem()}, nil, false)
+			fn := reflect.MakeFunc(ft, func([]reflect.Value) []reflect.Value { return nil })
+			require.NoError(t, c.Invoke(fn.Interface()))
+		}
+		assert.Len(t, c.invoked, 2)
+
+		e, err := c.Explain(new(*B))
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{"*dig.B"}, funcs(e.Roots))
+	})
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build go1.21
+// +build go1.21
+
+package dig
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+
+	"go.uber.org/dig/internal/digreflect"
+)
+
+// Provide provides ctor to the container like Container.Provide, after
+// checking that it returns a value of type T as its first result. The type
+// of the constructor is checked where it is provided rather than where its
+// value is first needed.
+//
+//   err := dig.Provide[*sql.DB](c, NewDB, dig.Name("ro"))
+func Provide[T any](c *Container, ctor any, opts ...ProvideOption) error {
+	rt, err := firstResult(ctor)
+	if err != nil {
+		return errf("can't provide %v", typeOf[T](), err)
+	}
+	if rt != typeOf[T]() {
+		return errf("can't provide %v: %v returns %v", typeOf[T](), reflect.TypeOf(ctor), rt)
+	}
+	return c.Provide(ctor, opts...)
+}
+
+// Invoke runs fn like Container.Invoke and returns its first result, which
+// must be of type T.
+//
+//   repo, err := dig.Invoke[*Repo](c, func(db *sql.DB) (*Repo, error) {
+//     return NewRepo(db)
+//   })
+func Invoke[T any](c *Container, fn any, opts ...InvokeOption) (T, error) {
+	var v T
+	rt, err := firstResult(fn)
+	if err != nil {
+		return v, errf("can't invoke for %v", typeOf[T](), err)
+	}
+	if !rt.AssignableTo(typeOf[T]()) {
+		return v, errf("can't invoke for %v: %v returns %v", typeOf[T](), reflect.TypeOf(fn), rt)
+	}
+
+	returned, err := c.invokeFunc(reflect.ValueOf(fn), digreflect.InspectFunc(fn), opts)
+	if err != nil {
+		return v, err
+	}
+	reflect.ValueOf(&v).Elem().Set(returned[0])
+	return v, nil
+}
+
+// Get returns the value of type T from the container, building it and its
+// dependencies if needed. It accepts the same options as Container.Get.
+//
+//   db, err := dig.Get[*sql.DB](c, dig.Name("ro"))
+func Get[T any](c *Container, opts ...ProvideOption) (T, error) {
+	return get[T](c, digreflect.InspectCaller(0), opts)
+}
+
+// MustGet is like Get but panics if the value cannot be built.
+func MustGet[T any](c *Container, opts ...ProvideOption) T {
+	v, err := get[T](c, digreflect.InspectCaller(0), opts)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func get[T any](c *Container, loc *digreflect.Func, opts []ProvideOption) (T, error) {
+	var v T
+	err := c.get(&v, loc, opts)
+	return v, err
+}
+
+// GetGroup returns the values of type T of the given value group. The name
+// Group is already taken by the ProvideOption.
+//
+//   handlers, err := dig.GetGroup[http.Handler](c, "routes")
+func GetGroup[T any](c *Container, group string) ([]T, error) {
+	var values []T
+	err := c.get(&values, digreflect.InspectCaller(0), []ProvideOption{Group(group)})
+	return values, err
+}
+
+// ProvideValue provides v to the container as a value of type T, with the
+// given options. T may be an interface implemented by v.
+//
+//   err := dig.ProvideValue[io.Writer](c, os.Stdout)
+//
+// The value is reported at the call to ProvideValue rather than inside dig.
+func ProvideValue[T any](c *Container, v T, opts ...ProvideOption) error {
+	loc := digreflect.InspectCaller(0)
+	opts = append(opts[:len(opts):len(opts)], provideOptionFunc(func(opts *provideOptions) {
+		opts.Location = loc
+	}))
+	return c.Provide(func() T { return v }, opts...)
+}
+
+// Lazy returns a function which builds the value of type T on its first
+// call and returns the same value and error on the following calls. The
+// container is not used until then.
+//
+//   getDB := dig.Lazy[*sql.DB](c)
+//   ...
+//   db, err := getDB()
+func Lazy[T any](c *Container, opts ...ProvideOption) func() (T, error) {
+	loc := digreflect.InspectCaller(0)
+	var (
+		once sync.Once
+		v    T
+		err  error
+	)
+	return func() (T, error) {
+		once.Do(func() {
+			v, err = get[T](c, loc, opts)
+		})
+		return v, err
+	}
+}
+
+// Passive registers fn with PassiveProvide after checking that it returns a
+// value of type T as its first result: values of type T requested by name
+// and not otherwise provided are built by calling fn with their name and its
+// other dependencies.
+//
+//   err := dig.Passive[*sql.DB](c, func(name string, cfg *Config) (*sql.DB, error) {
+//     return sql.Open("postgres", cfg.DSN[name])
+//   })
+func Passive[T any](c *Container, fn any, opts ...PassiveProvideOption) error {
+	rt, err := firstResult(fn)
+	if err != nil {
+		return errf("can't provide %v passively", typeOf[T](), err)
+	}
+	if rt != typeOf[T]() {
+		return errf("can't provide %v passively: %v returns %v", typeOf[T](), reflect.TypeOf(fn), rt)
+	}
+	return c.PassiveProvide(fn, opts...)
+}
+
+// firstResult returns the type of the first result of the function fn, which
+// must not be an error.
+func firstResult(fn any) (reflect.Type, error) {
+	ft := reflect.TypeOf(fn)
+	if ft == nil {
+		return nil, errors.New("got an untyped nil")
+	}
+	if ft.Kind() != reflect.Func {
+		return nil, errf("expected a function, got %v (type %v)", fn, ft)
+	}
+	if ft.NumOut() == 0 || isError(ft.Out(0)) {
+		return nil, errf("%v must return a value before any error", ft)
+	}
+	return ft.Out(0), nil
+}
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
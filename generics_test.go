@@ -0,0 +1,181 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build go1.21
+// +build go1.21
+
+package dig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerics(t *testing.T) {
+	type A struct{ Name string }
+
+	t.Run("Get and MustGet", func(t *testing.T) {
+		c := New()
+		require.NoError(t, ProvideValue(c, &A{Name: "default"}))
+		require.NoError(t, ProvideValue(c, &A{Name: "ro"}, Name("ro")))
+
+		a, err := Get[*A](c)
+		require.NoError(t, err)
+		assert.Equal(t, "default", a.Name)
+		assert.Equal(t, "ro", MustGet[*A](c, Name("ro")).Name)
+
+		_, err = Get[*DB](c)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "TestGenerics.func1")
+		assert.Contains(t, err.Error(), "missing type: *dig.DB")
+		assert.Panics(t, func() { MustGet[*DB](c) })
+	})
+
+	t.Run("ProvideValue with an interface", func(t *testing.T) {
+		c := New()
+		require.NoError(t, ProvideValue[fmt.Stringer](c, bytes.NewBufferString("foo")))
+		s := MustGet[fmt.Stringer](c)
+		assert.Equal(t, "foo", s.String())
+	})
+
+	t.Run("GetGroup", func(t *testing.T) {
+		c := New()
+		require.NoError(t, ProvideValue(c, "foo", Group("s")))
+		require.NoError(t, ProvideValue(c, "bar", Group("s")))
+
+		values, err := GetGroup[string](c, "s")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"foo", "bar"}, values)
+
+		empty, err := GetGroup[int](c, "none")
+		require.NoError(t, err)
+		assert.Empty(t, empty)
+	})
+
+	t.Run("Lazy", func(t *testing.T) {
+		c := New()
+		var calls int
+		require.NoError(t, c.Provide(func() *A {
+			calls++
+			return &A{Name: "lazy"}
+		}))
+
+		get := Lazy[*A](c)
+		assert.Zero(t, calls, "Lazy must not build the value")
+		a, err := get()
+		require.NoError(t, err)
+		assert.Equal(t, "lazy", a.Name)
+		b, err := get()
+		require.NoError(t, err)
+		assert.True(t, a == b)
+		assert.Equal(t, 1, calls)
+
+		_, err = Lazy[*DB](c)()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "TestGenerics.func4")
+	})
+
+	t.Run("Passive", func(t *testing.T) {
+		c := New()
+		require.NoError(t, Passive[*DB](c, func(name string) (*DB, error) {
+			if name == "bad" {
+				return nil, errors.New("great sadness")
+			}
+			return &DB{Name: name}, nil
+		}))
+
+		assert.Equal(t, "main", MustGet[*DB](c, Name("main")).Name)
+		_, err := Get[*DB](c, Name("bad"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "great sadness")
+	})
+
+	t.Run("Passive with dependencies", func(t *testing.T) {
+		c := New()
+		require.NoError(t, ProvideValue(c, &A{Name: "prefix"}))
+		require.NoError(t, Passive[*DB](c, func(name string, a *A) *DB {
+			return &DB{Name: a.Name + "-" + name}
+		}))
+		assert.Equal(t, "prefix-main", MustGet[*DB](c, Name("main")).Name)
+
+		err := Passive[*A](c, func(name string) *DB { return nil })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "can't provide *dig.A passively")
+	})
+
+	t.Run("Provide", func(t *testing.T) {
+		c := New()
+		require.NoError(t, Provide[*A](c, func() (*A, error) { return &A{Name: "a"}, nil }))
+		assert.Equal(t, "a", MustGet[*A](c).Name)
+
+		err := Provide[*A](c, func() *DB { return nil })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "can't provide *dig.A")
+
+		err = Provide[*A](c, &A{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "expected a function")
+	})
+
+	t.Run("Invoke", func(t *testing.T) {
+		c := New()
+		require.NoError(t, ProvideValue(c, &A{Name: "a"}))
+
+		name, err := Invoke[string](c, func(a *A) (string, error) { return a.Name, nil })
+		require.NoError(t, err)
+		assert.Equal(t, "a", name)
+
+		s, err := Invoke[fmt.Stringer](c, func(a *A) *bytes.Buffer {
+			return bytes.NewBufferString(a.Name)
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "a", s.String())
+
+		_, err = Invoke[string](c, func(*A) (string, error) { return "", errors.New("great sadness") })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "great sadness")
+
+		_, err = Invoke[int](c, func(*A) error { return nil })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must return a value before any error")
+
+		_, err = Invoke[int](c, func(*A) string { return "" })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "can't invoke for int")
+	})
+
+	t.Run("ProvideValue reports its caller", func(t *testing.T) {
+		c := New()
+		require.NoError(t, ProvideValue(c, &A{Name: "a"}))
+		require.NoError(t, ProvideValue(c, &A{Name: "b"}, Name("b")))
+		assert.NotEqual(t, c.nodes[0].ID(), c.nodes[1].ID())
+
+		err := ProvideValue(c, &A{Name: "c"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "TestGenerics.func9")
+		assert.Contains(t, err.Error(), "generics_test.go")
+		assert.NotContains(t, err.Error(), "generics.go")
+	})
+}
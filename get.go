@@ -0,0 +1,138 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+
+	"go.uber.org/dig/internal/digreflect"
+)
+
+// Get builds the value of the type pointed to by ptr, along with its
+// dependencies, and stores it in *ptr. It is a shorter form of a call to
+// Invoke with a function storing its parameter.
+//
+//   var db *sql.DB
+//   err := c.Get(&db, dig.Name("ro"))
+//
+// Only the Name and Group options may be given. With Group, ptr must point
+// to a slice of the type of the values of the group. If ptr points to a
+// struct embedding dig.In, its fields are filled as for the parameters of
+// a function passed to Invoke.
+func (c *Container) Get(ptr interface{}, opts ...ProvideOption) error {
+	return c.get(ptr, digreflect.InspectCaller(0), opts)
+}
+
+// get implements Get, reporting errors at loc.
+func (c *Container) get(ptr interface{}, loc *digreflect.Func, opts []ProvideOption) error {
+	pt := reflect.TypeOf(ptr)
+	if pt == nil {
+		return errors.New("can't get an untyped nil")
+	}
+	if pt.Kind() != reflect.Ptr {
+		return errf("can't get into non-pointer %v (type %v)", ptr, pt)
+	}
+	pv := reflect.ValueOf(ptr)
+	if pv.IsNil() {
+		return errf("can't get into a nil %v", pt)
+	}
+
+	var options provideOptions
+	for _, o := range opts {
+		o.applyProvideOption(&options)
+	}
+	if err := options.Validate(); err != nil {
+		return err
+	}
+	if len(options.Aliases) > 0 || options.Timeout != 0 {
+		return errors.New("only dig.Name and dig.Group may be used with Get")
+	}
+
+	t := pt.Elem()
+	paramType, field := t, -1
+	switch {
+	case IsIn(t):
+		if options.Name != "" || options.Group != "" {
+			return errf("can't use dig.Name or dig.Group to get %v: it embeds dig.In", t)
+		}
+	case options.Group != "":
+		if t.Kind() != reflect.Slice {
+			return errf("can't get value group %q into %v: value groups may be consumed as slices only", options.Group, t)
+		}
+		paramType, field = getParamType(t, "group:"+strconv.Quote(options.Group)), 1
+	case options.Name != "":
+		paramType, field = getParamType(t, "name:"+strconv.Quote(options.Name)), 1
+	}
+
+	ftype := reflect.FuncOf([]reflect.Type{paramType}, nil, false /* variadic */)
+	fn := reflect.MakeFunc(ftype, func(args []reflect.Value) []reflect.Value {
+		v := args[0]
+		if field >= 0 {
+			v = v.Field(field)
+		}
+		pv.Elem().Set(v)
+		return nil
+	})
+	_, err := c.invokeFunc(fn, loc, nil)
+	return err
+}
+
+// getParamType returns a struct embedding dig.In with a single field of type
+// t and the given tag.
+func getParamType(t reflect.Type, tag string) reflect.Type {
+	return reflect.StructOf([]reflect.StructField{
+		{Name: "In", Type: _inType, Anonymous: true},
+		{Name: "Value", Type: t, Tag: reflect.StructTag(tag)},
+	})
+}
+
+// InvokeResults runs the given function like Invoke, and returns the values
+// it returned, without the trailing error if the function returns one.
+//
+//   results, err := c.InvokeResults(func(db *sql.DB) (*Repo, error) {
+//     return NewRepo(db)
+//   })
+//   repo := results[0].(*Repo)
+func (c *Container) InvokeResults(function interface{}, opts ...InvokeOption) ([]interface{}, error) {
+	ftype := reflect.TypeOf(function)
+	if ftype == nil {
+		return nil, errors.New("can't invoke an untyped nil")
+	}
+	if ftype.Kind() != reflect.Func {
+		return nil, errf("can't invoke non-function %v (type %v)", function, ftype)
+	}
+
+	returned, err := c.invokeFunc(reflect.ValueOf(function), digreflect.InspectFunc(function), opts)
+	if err != nil {
+		return nil, err
+	}
+	if n := len(returned); n > 0 && isError(returned[n-1].Type()) {
+		returned = returned[:n-1]
+	}
+
+	results := make([]interface{}, len(returned))
+	for i, r := range returned {
+		results[i] = r.Interface()
+	}
+	return results, nil
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	type A struct{ Name string }
+	type B struct {
+		A *A `inject:"ro"`
+	}
+
+	newContainer := func(t *testing.T) *Container {
+		c := New()
+		require.NoError(t, c.Provide(func() *A { return &A{Name: "default"} }))
+		require.NoError(t, c.Provide(func() *A { return &A{Name: "ro"} }, Name("ro")))
+		require.NoError(t, c.Provide(func() *B { return &B{} }))
+		require.NoError(t, c.Provide(func() string { return "foo" }, Group("s")))
+		require.NoError(t, c.Provide(func() string { return "bar" }, Group("s")))
+		return c
+	}
+
+	t.Run("unnamed", func(t *testing.T) {
+		var a *A
+		require.NoError(t, newContainer(t).Get(&a))
+		assert.Equal(t, "default", a.Name)
+	})
+
+	t.Run("named", func(t *testing.T) {
+		var a *A
+		require.NoError(t, newContainer(t).Get(&a, Name("ro")))
+		assert.Equal(t, "ro", a.Name)
+	})
+
+	t.Run("group", func(t *testing.T) {
+		var s []string
+		require.NoError(t, newContainer(t).Get(&s, Group("s")))
+		assert.ElementsMatch(t, []string{"foo", "bar"}, s)
+	})
+
+	t.Run("dig.In", func(t *testing.T) {
+		var p struct {
+			In
+
+			A  *A       `name:"ro"`
+			S  []string `group:"s"`
+			DB *DB      `optional:"true"`
+		}
+		require.NoError(t, newContainer(t).Get(&p))
+		assert.Equal(t, "ro", p.A.Name)
+		assert.Len(t, p.S, 2)
+		assert.Nil(t, p.DB)
+	})
+
+	t.Run("inject tags", func(t *testing.T) {
+		var b *B
+		require.NoError(t, newContainer(t).Get(&b))
+		require.NotNil(t, b.A)
+		assert.Equal(t, "ro", b.A.Name)
+	})
+
+	t.Run("passive", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.PassiveProvide(func(name string) *DB { return &DB{Name: name} }))
+		var db *DB
+		require.NoError(t, c.Get(&db, Name("main")))
+		assert.Equal(t, "main", db.Name)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		var db *DB
+		err := newContainer(t).Get(&db)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing dependencies for function")
+		assert.Contains(t, err.Error(), "TestGet.func8")
+		assert.Contains(t, err.Error(), "get_test.go")
+		assert.Contains(t, err.Error(), "missing type: *dig.DB")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		c := newContainer(t)
+		var a *A
+		var p struct{ In }
+		tests := []struct {
+			desc string
+			ptr  interface{}
+			opts []ProvideOption
+			want string
+		}{
+			{"nil", nil, nil, "can't get an untyped nil"},
+			{"not a pointer", A{}, nil, "can't get into non-pointer"},
+			{"nil pointer", (**A)(nil), nil, "can't get into a nil **dig.A"},
+			{"name and group", &a, []ProvideOption{Name("ro"), Group("s")}, "cannot use named values with value groups"},
+			{"other options", &a, []ProvideOption{Timeout(time.Second)}, "only dig.Name and dig.Group may be used with Get"},
+			{"group into non-slice", &a, []ProvideOption{Group("s")}, "value groups may be consumed as slices only"},
+			{"named dig.In", &p, []ProvideOption{Name("ro")}, "it embeds dig.In"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.desc, func(t *testing.T) {
+				err := c.Get(tt.ptr, tt.opts...)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.want)
+			})
+		}
+	})
+}
+
+func TestInvokeResults(t *testing.T) {
+	type A struct{}
+
+	c := New()
+	require.NoError(t, c.Provide(func() *A { return &A{} }))
+
+	t.Run("results", func(t *testing.T) {
+		results, err := c.InvokeResults(func(a *A) (*A, string, error) {
+			return a, "foo", nil
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.IsType(t, &A{}, results[0])
+		assert.Equal(t, "foo", results[1])
+	})
+
+	t.Run("no results", func(t *testing.T) {
+		results, err := c.InvokeResults(func(*A) {})
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		_, err := c.InvokeResults(func(*A) (string, error) {
+			return "", errors.New("great sadness")
+		})
+		assert.EqualError(t, err, "great sadness")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := c.InvokeResults(nil)
+		assert.EqualError(t, err, "can't invoke an untyped nil")
+
+		_, err = c.InvokeResults(42)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "can't invoke non-function")
+	})
+}
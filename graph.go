@@ -21,7 +21,9 @@
 package dig
 
 import (
+	"fmt"
 	"io"
+	"reflect"
 	"strconv"
 	"text/template"
 
@@ -35,6 +37,12 @@ type VisualizeOption interface {
 
 type visualizeOptions struct {
 	VisualizeError error
+	Format         GraphFormat
+	Roots          []interface{}
+	Depth          int
+	Packages       []string
+	HideGroups     bool
+	Runtime        bool
 }
 
 type visualizeOptionFunc func(*visualizeOptions)
@@ -56,7 +64,172 @@ func VisualizeError(err error) VisualizeOption {
 	})
 }
 
+// GraphFormat is the output format of Visualize.
+type GraphFormat int
+
+const (
+	// FormatDOT writes the graph in the DOT language of Graphviz. This is
+	// the default.
+	FormatDOT GraphFormat = iota
+
+	// FormatMermaid writes the graph as a Mermaid flowchart, which may be
+	// embedded in Markdown documents.
+	FormatMermaid
+
+	// FormatGraphML writes the graph as a GraphML document, which may be
+	// loaded into tools such as yEd or Gephi.
+	FormatGraphML
+
+	// FormatJSON writes the nodes and edges of the graph as a JSON document.
+	FormatJSON
+)
+
+func (f GraphFormat) String() string {
+	switch f {
+	case FormatDOT:
+		return "dot"
+	case FormatMermaid:
+		return "mermaid"
+	case FormatGraphML:
+		return "graphml"
+	case FormatJSON:
+		return "json"
+	default:
+		return fmt.Sprintf("GraphFormat(%d)", int(f))
+	}
+}
+
+// VisualizeFormat sets the output format of Visualize.
+//
+//   dig.Visualize(c, w, dig.VisualizeFormat(dig.FormatMermaid))
+//
+// Failures visualized with VisualizeError are highlighted in every format.
+func VisualizeFormat(f GraphFormat) VisualizeOption {
+	return visualizeOptionFunc(func(opts *visualizeOptions) {
+		opts.Format = f
+	})
+}
+
+// VisualizeRoots limits the output of Visualize to the constructors needed,
+// directly or transitively, to build the given roots. Each root is either a
+// pointer to the type of an unnamed value, or a function whose parameters
+// are the roots, such as the functions passed to Invoke.
+//
+//   dig.Visualize(c, w, dig.VisualizeRoots(new(*http.Server), app.Run))
+func VisualizeRoots(roots ...interface{}) VisualizeOption {
+	return visualizeOptionFunc(func(opts *visualizeOptions) {
+		opts.Roots = append(opts.Roots, roots...)
+	})
+}
+
+// VisualizeDepth limits the output of Visualize to the constructors at most
+// depth steps away from the roots given with VisualizeRoots. The
+// constructors of the roots are one step away, their dependencies two steps
+// away, and so on. A depth of zero or less does not limit the output.
+//
+// This option has no effect without VisualizeRoots.
+func VisualizeDepth(depth int) VisualizeOption {
+	return visualizeOptionFunc(func(opts *visualizeOptions) {
+		opts.Depth = depth
+	})
+}
+
+// VisualizePackages limits the output of Visualize to constructors defined
+// in the given packages. A pattern ending with "/..." matches a package and
+// all packages below it.
+//
+//   dig.Visualize(c, w, dig.VisualizePackages("github.com/us/payments/..."))
+func VisualizePackages(patterns ...string) VisualizeOption {
+	return visualizeOptionFunc(func(opts *visualizeOptions) {
+		opts.Packages = append(opts.Packages, patterns...)
+	})
+}
+
+// VisualizeHideGroups leaves value groups out of the output of Visualize.
+func VisualizeHideGroups() VisualizeOption {
+	return visualizeOptionFunc(func(opts *visualizeOptions) {
+		opts.HideGroups = true
+	})
+}
+
+// VisualizeRuntime annotates each constructor in the output of Visualize with
+// what happened when the container ran: whether it was called, how long it
+// took not including its dependencies if the container was created with
+// ProfileConstructors, how many times its values were passed to other
+// functions, whether it was generated by PassiveProvide, and whether its
+// values populated inject-tagged fields. Constructors that were not called
+// are drawn with dashed lines.
+func VisualizeRuntime() VisualizeOption {
+	return visualizeOptionFunc(func(opts *visualizeOptions) {
+		opts.Runtime = true
+	})
+}
+
+// filterGraph removes the parts of the graph that were not requested by the
+// options.
+func filterGraph(dg *dot.Graph, opts visualizeOptions) error {
+	if len(opts.Roots) > 0 {
+		var roots []*dot.Node
+		for _, r := range opts.Roots {
+			nodes, err := rootNodes(r)
+			if err != nil {
+				return errf("invalid root for Visualize", err)
+			}
+			roots = append(roots, nodes...)
+		}
+		dg.PruneUnreachable(roots, opts.Depth)
+	}
+
+	if len(opts.Packages) > 0 {
+		dg.PrunePackages(opts.Packages)
+	}
+
+	if opts.HideGroups {
+		dg.HideGroups()
+	}
+	return nil
+}
+
+// rootNodes returns the nodes of the values requested by a root passed to
+// VisualizeRoots.
+func rootNodes(root interface{}) ([]*dot.Node, error) {
+	t := reflect.TypeOf(root)
+	switch {
+	case t == nil:
+		return nil, errf("can't use an untyped nil as a root")
+	case t.Kind() == reflect.Ptr:
+		return []*dot.Node{{Type: t.Elem()}}, nil
+	case t.Kind() == reflect.Func:
+		pl, err := newParamList(t)
+		if err != nil {
+			return nil, err
+		}
+		var nodes []*dot.Node
+		for _, p := range pl.DotParam() {
+			nodes = append(nodes, p.Node)
+		}
+		return nodes, nil
+	default:
+		return nil, errf("expected a function or a pointer to a type, got %v (type %v)", root, t)
+	}
+}
+
 func updateGraph(dg *dot.Graph, err error) error {
+	// If there are no errVisualizers included, we do not modify the graph.
+	if !visualizeErr(dg, err) {
+		return nil
+	}
+
+	// Remove non-error entries from the graph for readability.
+	dg.PruneSuccess()
+
+	return nil
+}
+
+// visualizeErr applies the errVisualizers found in the chain of the given
+// error to the graph, starting with the root cause. It reports whether the
+// chain included any errVisualizers.
+func visualizeErr(dg *dot.Graph, err error) bool {
 	var errors []errVisualizer
 	// Unwrap error to find the root cause.
 	for {
@@ -70,20 +243,11 @@ func updateGraph(dg *dot.Graph, err error) error {
 		err = e.cause()
 	}
 
-	// If there are no errVisualizers included, we do not modify the graph.
-	if len(errors) == 0 {
-		return nil
-	}
-
 	// We iterate in reverse because the last element is the root cause.
 	for i := len(errors) - 1; i >= 0; i-- {
 		errors[i].updateGraph(dg)
 	}
-
-	// Remove non-error entries from the graph for readability.
-	dg.PruneSuccess()
-
-	return nil
+	return len(errors) > 0
 }
 
 var _graphTmpl = template.Must(
@@ -105,7 +269,12 @@ var _graphTmpl = template.Must(
 			{{ with .Package }}label = {{ quote .}};
 			{{ end -}}
 
+			{{- if .Runtime -}}
+			constructor_{{$index}} [shape=plaintext label=<{{html .Name}}<BR /><FONT POINT-SIZE="10">{{html .Runtime}}</FONT>>];
+			{{if not .Runtime.Called}}style=dashed;{{end}}
+			{{- else -}}
 			constructor_{{$index}} [shape=plaintext label={{quote .Name}}];
+			{{- end}}
 			{{with .ErrorType}}color={{.Color}};{{end}}
 			{{range .Results}}
 				{{- quote .String}} [{{.Attributes}}];
@@ -118,7 +287,10 @@ var _graphTmpl = template.Must(
 			constructor_{{$index}} -> {{quote .String}} [ltail=cluster_{{$index}}];
 		{{end -}}
 	{{end}}
-	{{range .Failed.TransitiveFailures}}
+	{{range .Aliases}}
+		{{- quote .String}} [{{.Attributes}}];
+	{{quote .String}} -> {{quote .TargetString}} [style=dashed];
+	{{end}}{{range .Failed.TransitiveFailures}}
 		{{- quote .String}} [color=orange];
 	{{end -}}
 	{{range .Failed.RootCauses}}
@@ -127,22 +299,48 @@ var _graphTmpl = template.Must(
 }`))
 
 // Visualize parses the graph in Container c into DOT format and writes it to
-// io.Writer w.
+// io.Writer w. Use VisualizeFormat to pick another output format.
 func Visualize(c *Container, w io.Writer, opts ...VisualizeOption) error {
-	dg := c.createGraph()
+	dg, _, options, err := c.visualizedGraph(opts)
+	if err != nil {
+		return err
+	}
+
+	switch options.Format {
+	case FormatDOT:
+		return _graphTmpl.Execute(w, dg)
+	case FormatMermaid:
+		return dg.WriteMermaid(w)
+	case FormatGraphML:
+		return dg.WriteGraphML(w)
+	case FormatJSON:
+		return dg.WriteJSON(w)
+	default:
+		return errf("unknown graph format %v", options.Format)
+	}
+}
 
+// visualizedGraph returns the graph of the container, updated with the error
+// and filtered as requested by the options, and the node that each of its
+// constructors was built from.
+func (c *Container) visualizedGraph(opts []VisualizeOption) (*dot.Graph, map[*dot.Ctor]*node, visualizeOptions, error) {
 	var options visualizeOptions
 	for _, o := range opts {
 		o.applyVisualizeOption(&options)
 	}
 
+	dg, nodes := c.buildGraph(options.Runtime)
+
 	if options.VisualizeError != nil {
 		if err := updateGraph(dg, options.VisualizeError); err != nil {
-			return err
+			return nil, nil, options, err
 		}
 	}
 
-	return _graphTmpl.Execute(w, dg)
+	if err := filterGraph(dg, options); err != nil {
+		return nil, nil, options, err
+	}
+	return dg, nodes, options, nil
 }
 
 // CanVisualizeError returns true if the error is an errVisualizer.
@@ -162,21 +360,46 @@ func CanVisualizeError(err error) bool {
 }
 
 func (c *Container) createGraph() *dot.Graph {
+	dg, _ := c.buildGraph(false)
+	return dg
+}
+
+// buildGraph returns the graph of the container and the node that each of
+// its constructors was built from. If runtime is set, each constructor is
+// annotated with what happened to its node.
+func (c *Container) buildGraph(runtime bool) (*dot.Graph, map[*dot.Ctor]*node) {
 	dg := dot.NewGraph()
+	nodes := make(map[*dot.Ctor]*node, len(c.nodes))
 
 	for _, n := range c.nodes {
-		dg.AddCtor(newDotCtor(n), n.paramList.DotParam(), n.resultList.DotResult())
+		dc := newDotCtor(n)
+		if runtime {
+			dc.Runtime = newDotRuntime(n)
+		}
+		nodes[dc] = n
+		dg.AddCtor(dc, n.paramList.DotParam(), n.resultList.DotResult())
 	}
+	c.addDotAliases(dg)
 
-	return dg
+	return dg, nodes
 }
 
 func newDotCtor(n *node) *dot.Ctor {
 	return &dot.Ctor{
-		ID:      n.id,
+		ID:      n.ID(),
 		Name:    n.location.Name,
 		Package: n.location.Package,
 		File:    n.location.File,
 		Line:    n.location.Line,
 	}
 }
+
+func newDotRuntime(n *node) *dot.Runtime {
+	return &dot.Runtime{
+		Called:   n.called,
+		Duration: n.duration,
+		Consumed: n.consumed,
+		Passive:  n.passive,
+		Injected: n.injected,
+	}
+}
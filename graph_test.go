@@ -21,10 +21,12 @@
 package dig
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -365,7 +367,7 @@ func TestNewDotCtor(t *testing.T) {
 	}
 
 	ctor := newDotCtor(n)
-	assert.Equal(t, n.id, ctor.ID)
+	assert.Equal(t, n.ID(), ctor.ID)
 	assert.Equal(t, "function1", ctor.Name)
 	assert.Equal(t, "pkg1", ctor.Package)
 	assert.Equal(t, "file1", ctor.File)
@@ -545,6 +547,205 @@ func TestVisualize(t *testing.T) {
 
 		VerifyVisualization(t, "missingDep", c, VisualizeError(err))
 	})
+
+	t.Run("aliased types", func(t *testing.T) {
+		c := New()
+
+		type in struct {
+			In
+
+			A t1 `name:"old"`
+			B t1 `name:"older"`
+		}
+
+		c.Provide(func() t1 { return t1{} }, Names("new", "old"))
+		c.Provide(func(in) t2 { return t2{} })
+		c.Alias("older", "old", new(t1))
+		VerifyVisualization(t, "alias", c)
+	})
+
+	t.Run("formats", func(t *testing.T) {
+		type in struct {
+			In
+
+			A t1   `optional:"true"`
+			B []t2 `group:"g"`
+			C t3   `name:"alias"`
+		}
+
+		type out struct {
+			Out
+
+			B t2 `group:"g"`
+			C t3 `name:"c"`
+		}
+
+		c := New()
+		c.Provide(func() out { return out{} })
+		c.Provide(func(in) t4 { return t4{} })
+		c.Alias("alias", "c", new(t3))
+		pinLocations(c)
+
+		for _, f := range []GraphFormat{FormatMermaid, FormatGraphML, FormatJSON} {
+			t.Run(f.String(), func(t *testing.T) {
+				VerifyVisualization(t, "formats", c, VisualizeFormat(f))
+			})
+		}
+	})
+
+	t.Run("formats with error", func(t *testing.T) {
+		c := New()
+		c.Provide(func(t1) (t2, error) { return t2{}, errf("great sadness") })
+		c.Provide(func(t2) t3 { return t3{} })
+		err := c.Invoke(func(t3) {})
+		pinLocations(c)
+
+		for _, f := range []GraphFormat{FormatMermaid, FormatGraphML, FormatJSON} {
+			t.Run(f.String(), func(t *testing.T) {
+				VerifyVisualization(t, "formats_error", c, VisualizeFormat(f), VisualizeError(err))
+			})
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		var b bytes.Buffer
+		err := Visualize(New(), &b, VisualizeFormat(GraphFormat(42)))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown graph format GraphFormat(42)")
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		type in struct {
+			In
+
+			A t1
+			B []t2 `group:"g"`
+		}
+
+		type out struct {
+			Out
+
+			B t2 `group:"g"`
+		}
+
+		c := New()
+		c.Provide(func() t1 { return t1{} })
+		c.Provide(func() out { return out{} })
+		c.Provide(func(in) t3 { return t3{} })
+		c.Provide(func(t3) t4 { return t4{} })
+		c.Provide(func() string { return "" })
+
+		VerifyVisualization(t, "roots", c, VisualizeRoots(new(t4)))
+		VerifyVisualization(t, "roots_func", c, VisualizeRoots(func(t1, string) {}))
+		VerifyVisualization(t, "depth", c, VisualizeRoots(new(t4)), VisualizeDepth(1))
+		VerifyVisualization(t, "hide_groups", c, VisualizeRoots(new(t4)), VisualizeHideGroups())
+		VerifyVisualization(t, "packages", c, VisualizePackages("example.com/..."))
+	})
+
+	t.Run("invalid root", func(t *testing.T) {
+		var b bytes.Buffer
+		err := Visualize(New(), &b, VisualizeRoots(t1{}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "expected a function or a pointer to a type")
+	})
+
+	t.Run("runtime", func(t *testing.T) {
+		c := New()
+		c.Provide(func() t1 { return t1{} })
+		c.Provide(func(t1) t2 { return t2{} })
+		c.Provide(func() t3 { return t3{} })
+		require.NoError(t, c.Invoke(func(t1, t2) {}))
+
+		// Durations must be stable in the golden files.
+		for _, n := range c.nodes {
+			if n.called {
+				n.duration = time.Millisecond
+			}
+		}
+
+		VerifyVisualization(t, "runtime", c, VisualizeRuntime())
+		VerifyVisualization(t, "runtime", c, VisualizeRuntime(), VisualizeFormat(FormatMermaid))
+	})
+
+	t.Run("roots with a constructor provided twice", func(t *testing.T) {
+		type in struct {
+			In
+
+			A t1 `name:"three"`
+		}
+
+		newT1 := func() t1 { return t1{} }
+		c := New()
+		require.NoError(t, c.Provide(newT1, Name("one")))
+		require.NoError(t, c.Provide(newT1, Name("three")))
+
+		var b bytes.Buffer
+		require.NoError(t, Visualize(c, &b, VisualizeRoots(func(in) {})))
+		assert.Contains(t, b.String(), "name=three")
+		assert.NotContains(t, b.String(), "name=one")
+	})
+}
+
+func TestVisualizeRuntime(t *testing.T) {
+	type injected struct {
+		DB *DB `inject:"db_main"`
+	}
+
+	c := New()
+	require.NoError(t, c.Provide(func() *Factory { return &Factory{} }))
+	require.NoError(t, c.PassiveProvide(func(name string, f *Factory) *DB { return f.Get(name) }))
+	require.NoError(t, c.Provide(func() *injected { return &injected{} }))
+	require.NoError(t, c.Invoke(func(*injected) {}))
+
+	runtimes := make(map[string]*dot.Runtime)
+	dg, _ := c.buildGraph(true)
+	for _, dc := range dg.Ctors {
+		for _, r := range dc.Results {
+			runtimes[r.String()] = dc.Runtime
+		}
+	}
+
+	require.Contains(t, runtimes, "*dig.DB[name=db_main]")
+	db := runtimes["*dig.DB[name=db_main]"]
+	assert.True(t, db.Called)
+	assert.True(t, db.Passive, "passive constructors must be marked")
+	assert.True(t, db.Injected, "constructors of injected values must be marked")
+	assert.Equal(t, 1, db.Consumed)
+
+	assert.Equal(t, 1, runtimes["*dig.Factory"].Consumed)
+	assert.False(t, runtimes["*dig.Factory"].Passive)
+	assert.False(t, runtimes["*dig.Factory"].Injected)
+
+	t.Run("clone without values", func(t *testing.T) {
+		clone := c.Clone()
+		for _, n := range clone.nodes {
+			assert.Zero(t, n.consumed)
+			assert.Zero(t, n.duration)
+		}
+	})
+
+	t.Run("duplicate constructors", func(t *testing.T) {
+		type Conn struct{}
+		newConn := func() *Conn { return &Conn{} }
+
+		c := New()
+		require.NoError(t, c.Provide(newConn, Name("ro")))
+		require.NoError(t, c.Provide(newConn, Name("rw")))
+		require.NoError(t, c.Invoke(func(struct {
+			In
+
+			Conn *Conn `name:"ro"`
+		}) {
+		}))
+
+		called := make(map[string]bool)
+		dg, _ := c.buildGraph(true)
+		for _, dc := range dg.Ctors {
+			require.Len(t, dc.Results, 1)
+			called[dc.Results[0].Name] = dc.Runtime.Called
+		}
+		assert.Equal(t, map[string]bool{"ro": true, "rw": false}, called)
+	})
 }
 
 type visualizableErr struct{}
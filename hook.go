@@ -0,0 +1,113 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"reflect"
+
+	"go.uber.org/dig/internal/digreflect"
+)
+
+// CallInfo describes a function about to be called by a container.
+type CallInfo struct {
+	// Func is where the function is defined.
+	Func Location
+
+	// Invoke is true if the function was passed to Invoke, and false if it
+	// is a constructor.
+	Invoke bool
+
+	// Params are the values the function depends on.
+	Params []KeyInfo
+
+	// Results are the values produced by a constructor. They are empty for
+	// functions passed to Invoke.
+	Results []KeyInfo
+
+	// Args are the arguments the function is called with.
+	Args []reflect.Value
+}
+
+// CallHook wraps the calls to constructors and to functions passed to
+// Invoke. It calls next to call the function, and returns the values the
+// function returned, or other values of the same types.
+type CallHook func(info CallInfo, next func() []reflect.Value) []reflect.Value
+
+// WithCallHook is an Option that adds a hook around every call to a
+// constructor or to a function passed to Invoke, for example to recover
+// panics, add tracing spans or log arguments.
+//
+//   c := dig.New(dig.WithCallHook(func(info dig.CallInfo, next func() []reflect.Value) []reflect.Value {
+//     span := tracer.StartSpan(info.Func.Name)
+//     defer span.Finish()
+//     return next()
+//   }))
+//
+// The option may be given several times. The first hook is the outermost
+// one.
+func WithCallHook(h CallHook) Option {
+	return optionFunc(func(c *Container) {
+		c.hooks = append(c.hooks, h)
+	})
+}
+
+func (c *Container) callHooks() []CallHook {
+	return c.hooks
+}
+
+// call calls fn, defined at f, with the arguments in info through the call
+// hooks and the invoker of the container, and checks that the hooks returned
+// values of the types returned by fn. Panics are returned as
+// errConstructorPanicked if the container recovers them.
+func call(c containerStore, f *digreflect.Func, info CallInfo, fn reflect.Value) (_ []reflect.Value, err error) {
+	if c.recoversPanics() {
+		defer recoverPanic(f, &err)
+	}
+
+	invoker := c.invoker()
+	next := func() []reflect.Value { return invoker(fn, info.Args) }
+	hooks := c.callHooks()
+	for i := len(hooks) - 1; i >= 0; i-- {
+		h, inner := hooks[i], next
+		next = func() []reflect.Value { return h(info, inner) }
+	}
+	results := next()
+
+	ft := fn.Type()
+	if len(results) != ft.NumOut() {
+		return nil, errf("call hook returned %d values, expected %d", len(results), ft.NumOut())
+	}
+	for i, r := range results {
+		if !r.IsValid() || !r.Type().AssignableTo(ft.Out(i)) {
+			return nil, errf("call hook returned invalid value %v for result %d of type %v", r, i, ft.Out(i))
+		}
+	}
+	return results, nil
+}
+
+// keyInfos converts keys to their public form.
+func keyInfos(keys []key) []KeyInfo {
+	infos := make([]KeyInfo, len(keys))
+	for i, k := range keys {
+		infos[i] = k.info()
+	}
+	return infos
+}
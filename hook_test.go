@@ -0,0 +1,133 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallHook(t *testing.T) {
+	type A struct{}
+	type B struct{}
+
+	t.Run("order and info", func(t *testing.T) {
+		var calls []string
+		hook := func(name string) CallHook {
+			return func(info CallInfo, next func() []reflect.Value) []reflect.Value {
+				calls = append(calls, fmt.Sprintf("%v before %v %v %v -> %v (%d args)",
+					name, info.Func.Name, info.Invoke, info.Params, info.Results, len(info.Args)))
+				defer func() { calls = append(calls, name+" after "+info.Func.Name) }()
+				return next()
+			}
+		}
+
+		c := New(WithCallHook(hook("outer")), WithCallHook(hook("inner")))
+		require.NoError(t, c.Provide(func() *A { return &A{} }))
+		require.NoError(t, c.Provide(func(*A) *B { return &B{} }))
+		require.NoError(t, c.Invoke(func(*B) {}))
+
+		assert.Equal(t, []string{
+			"outer before TestCallHook.func1.2 false [] -> [*dig.A] (0 args)",
+			"inner before TestCallHook.func1.2 false [] -> [*dig.A] (0 args)",
+			"inner after TestCallHook.func1.2",
+			"outer after TestCallHook.func1.2",
+			"outer before TestCallHook.func1.3 false [*dig.A] -> [*dig.B] (1 args)",
+			"inner before TestCallHook.func1.3 false [*dig.A] -> [*dig.B] (1 args)",
+			"inner after TestCallHook.func1.3",
+			"outer after TestCallHook.func1.3",
+			"outer before TestCallHook.func1.4 true [*dig.B] -> [] (1 args)",
+			"inner before TestCallHook.func1.4 true [*dig.B] -> [] (1 args)",
+			"inner after TestCallHook.func1.4",
+			"outer after TestCallHook.func1.4",
+		}, calls)
+	})
+
+	t.Run("replace results", func(t *testing.T) {
+		fake := &A{}
+		c := New(WithCallHook(func(info CallInfo, next func() []reflect.Value) []reflect.Value {
+			if info.Invoke {
+				return next()
+			}
+			return []reflect.Value{reflect.ValueOf(fake)}
+		}))
+		require.NoError(t, c.Provide(func() *A { t.Fatal("must not be called"); return nil }))
+		require.NoError(t, c.Invoke(func(a *A) {
+			assert.True(t, a == fake)
+		}))
+	})
+
+	t.Run("recover panics", func(t *testing.T) {
+		c := New(WithCallHook(func(info CallInfo, next func() []reflect.Value) (results []reflect.Value) {
+			defer func() {
+				if r := recover(); r != nil {
+					results = []reflect.Value{
+						reflect.Zero(reflect.TypeOf(&A{})),
+						reflect.ValueOf(fmt.Errorf("panic: %v", r)),
+					}
+				}
+			}()
+			return next()
+		}))
+		require.NoError(t, c.Provide(func() (*A, error) { panic("great sadness") }))
+		err := c.Invoke(func(*A) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "panic: great sadness")
+	})
+
+	t.Run("invalid results", func(t *testing.T) {
+		c := New(WithCallHook(func(CallInfo, func() []reflect.Value) []reflect.Value {
+			return nil
+		}))
+		require.NoError(t, c.Provide(func() *A { return &A{} }))
+		err := c.Invoke(func(*A) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "call hook returned 0 values, expected 1")
+
+		c = New(WithCallHook(func(CallInfo, func() []reflect.Value) []reflect.Value {
+			return []reflect.Value{reflect.ValueOf(errors.New("not an A"))}
+		}))
+		require.NoError(t, c.Provide(func() *A { return &A{} }))
+		err = c.Invoke(func(*A) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "call hook returned invalid value")
+
+		err = c.Invoke(func() {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "call hook returned 1 values, expected 0")
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		var called bool
+		c := New(DryRun(true), WithCallHook(func(info CallInfo, next func() []reflect.Value) []reflect.Value {
+			called = true
+			return next()
+		}))
+		require.NoError(t, c.Provide(func() *A { t.Fatal("must not be called"); return nil }))
+		require.NoError(t, c.Invoke(func(*A) {}))
+		assert.True(t, called)
+	})
+}
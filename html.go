@@ -0,0 +1,318 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+
+	"go.uber.org/dig/internal/dot"
+)
+
+// htmlGraph is the data embedded in the page written by VisualizeHTML.
+type htmlGraph struct {
+	*dot.Elements
+
+	// Built holds the IDs of the constructors that were called and of the
+	// values that were built.
+	Built []string `json:"built"`
+
+	// Error is the message of the error given with VisualizeError.
+	Error string `json:"error,omitempty"`
+}
+
+// VisualizeHTML writes the graph in Container c to io.Writer w as a single
+// HTML page to explore it in a browser. The page has no external
+// dependencies and works offline.
+//
+// Constructors and values may be searched by name, type or package.
+// Selecting one highlights everything it depends on and everything that
+// depends on it, and shows where the constructor is defined. Values which
+// the container already built are listed separately from the values that
+// were not built yet.
+//
+// All VisualizeOptions but VisualizeFormat are supported. Failures given
+// with VisualizeError are highlighted and the error is shown in the page.
+func VisualizeHTML(c *Container, w io.Writer, opts ...VisualizeOption) error {
+	dg, nodes, options, err := c.visualizedGraph(opts)
+	if err != nil {
+		return err
+	}
+
+	g := htmlGraph{
+		Elements: dg.Elements(),
+		Built:    c.builtElements(dg, nodes),
+	}
+	if options.VisualizeError != nil {
+		g.Error = fmt.Sprint(options.VisualizeError)
+	}
+	return _htmlTmpl.Execute(w, g)
+}
+
+// builtElements returns the IDs of the elements of the graph that were
+// already built by the container.
+func (c *Container) builtElements(dg *dot.Graph, nodes map[*dot.Ctor]*node) []string {
+	var built []string
+	values := make(map[string]struct{})
+	for i, dc := range dg.Ctors {
+		n, ok := nodes[dc]
+		if !ok {
+			continue
+		}
+		if n.called {
+			built = append(built, fmt.Sprintf("constructor_%d", i))
+		}
+		for _, r := range dc.Results {
+			if r.Group != "" {
+				if !n.called {
+					continue
+				}
+			} else if _, ok := c.values[key{t: r.Type, name: r.Name}]; !ok {
+				continue
+			}
+			built = append(built, r.String())
+			values[r.String()] = struct{}{}
+		}
+	}
+
+	for _, a := range dg.Aliases {
+		if _, ok := values[a.TargetString()]; ok {
+			built = append(built, a.String())
+		}
+	}
+	return built
+}
+
+var _htmlTmpl = template.Must(template.New("HTMLGraph").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>dig container</title>
+<style>
+body { margin: 0; font: 13px sans-serif; display: flex; height: 100vh; color: #222; }
+aside { width: 280px; border-right: 1px solid #ccc; display: flex; flex-direction: column; }
+aside input { margin: 8px; padding: 4px; }
+aside ul { list-style: none; margin: 0; padding: 0; overflow: auto; flex: 1; }
+aside li { padding: 3px 8px; cursor: pointer; white-space: nowrap; overflow: hidden; text-overflow: ellipsis; }
+aside li:hover, aside li.selected { background: #e8eefc; }
+main { flex: 1; overflow: auto; }
+section { width: 320px; border-left: 1px solid #ccc; overflow: auto; padding: 8px; }
+h2 { font-size: 14px; margin: 12px 0 4px; }
+pre { white-space: pre-wrap; background: #fff0f0; padding: 4px; }
+.kind { color: #888; font-size: 11px; }
+.link { color: #2a5db0; cursor: pointer; }
+.root-cause { color: red; }
+.transitive-failure { color: orange; }
+svg .vertex rect { fill: #fff; stroke: #999; }
+svg .vertex.constructor rect { fill: #eef0ff; }
+svg .vertex.built rect { fill: #e6f6e6; }
+svg .vertex.root-cause rect { stroke: red; stroke-width: 2; }
+svg .vertex.transitive-failure rect { stroke: orange; stroke-width: 2; }
+svg .vertex { cursor: pointer; }
+svg .edge { fill: none; stroke: #bbb; }
+svg .edge.optional { stroke-dasharray: 4 3; }
+svg.focus .vertex, svg.focus .edge { opacity: 0.2; }
+svg.focus .dep, svg.focus .user, svg.focus .selected { opacity: 1; }
+svg .vertex.selected rect { stroke: #2a5db0; stroke-width: 3; }
+svg .edge.dep { stroke: #2a5db0; }
+svg .edge.user { stroke: #b02a8f; }
+</style>
+</head>
+<body>
+<aside>
+<input id="search" type="search" placeholder="Search constructors and types">
+<ul id="list"></ul>
+</aside>
+<main><svg id="graph" xmlns="http://www.w3.org/2000/svg"></svg></main>
+<section>
+<div id="error"></div>
+<div id="details"><p>Select a constructor or a value.</p></div>
+<h2>Built values</h2><ul id="built"></ul>
+<h2>Values not built yet</h2><ul id="pending"></ul>
+</section>
+<script>
+(function() {
+const data = {{.}};
+const nodes = data.nodes || [], edges = data.edges || [];
+const byId = new Map(nodes.map(n => [n.id, n]));
+const built = new Set(data.built || []);
+const deps = new Map(), users = new Map();
+nodes.forEach(n => { deps.set(n.id, []); users.set(n.id, []); });
+// Every edge points from a vertex to a vertex it depends on.
+const links = edges.map(e => e.kind === "provides" ?
+	{from: e.to, to: e.from, edge: e} : {from: e.from, to: e.to, edge: e});
+links.forEach(l => { deps.get(l.from).push(l.to); users.get(l.to).push(l.from); });
+
+function el(tag, attrs, text) {
+	const ns = ["svg", "g", "rect", "text", "path", "title"].includes(tag) ?
+		"http://www.w3.org/2000/svg" : "http://www.w3.org/1999/xhtml";
+	const e = document.createElementNS(ns, tag);
+	Object.keys(attrs || {}).forEach(k => e.setAttribute(k, attrs[k]));
+	if (text !== undefined) e.textContent = text;
+	return e;
+}
+
+function label(n) {
+	let s = n.kind === "constructor" && n.package ? n.package + "." + n.label : n.label;
+	if (n.name) s += " [name=" + n.name + "]";
+	if (n.group) s += " [group=" + n.group + "]";
+	return s;
+}
+
+// Vertices are laid out in columns by the length of their longest chain of
+// dependencies.
+const column = new Map();
+function depth(id, visiting) {
+	if (column.has(id)) return column.get(id);
+	if (visiting.has(id)) return 0;
+	visiting.add(id);
+	let d = 0;
+	deps.get(id).forEach(x => { d = Math.max(d, depth(x, visiting) + 1); });
+	visiting.delete(id);
+	column.set(id, d);
+	return d;
+}
+nodes.forEach(n => depth(n.id, new Set()));
+
+const W = 200, H = 28, DX = 260, DY = 40;
+const rows = [], pos = new Map();
+nodes.forEach(n => {
+	const c = column.get(n.id);
+	rows[c] = (rows[c] || 0) + 1;
+	pos.set(n.id, {x: 20 + c * DX, y: 20 + (rows[c] - 1) * DY});
+});
+const svg = document.getElementById("graph");
+svg.setAttribute("width", 40 + rows.length * DX);
+svg.setAttribute("height", 40 + Math.max(1, ...rows.map(r => r || 0)) * DY);
+
+const edgeEls = links.map(l => {
+	const a = pos.get(l.from), b = pos.get(l.to);
+	const x1 = a.x, y1 = a.y + H / 2, x2 = b.x + W, y2 = b.y + H / 2;
+	const p = el("path", {
+		"class": "edge" + (l.edge.optional || l.edge.kind === "alias" ? " optional" : ""),
+		d: "M" + x1 + "," + y1 + " C" + (x1 - 30) + "," + y1 + " " + (x2 + 30) + "," + y2 + " " + x2 + "," + y2,
+	});
+	svg.appendChild(p);
+	return {link: l, el: p};
+});
+
+const vertexEls = new Map();
+nodes.forEach(n => {
+	const p = pos.get(n.id);
+	const classes = ["vertex", n.kind];
+	if (n.status) classes.push(n.status);
+	if (built.has(n.id)) classes.push("built");
+	const g = el("g", {"class": classes.join(" "), transform: "translate(" + p.x + "," + p.y + ")"});
+	g.appendChild(el("rect", {width: W, height: H, rx: n.kind === "constructor" ? 0 : 8}));
+	const t = label(n);
+	g.appendChild(el("text", {x: 6, y: 18}, t.length > 30 ? t.slice(0, 29) + "…" : t));
+	g.appendChild(el("title", {}, t));
+	g.addEventListener("click", () => select(n.id));
+	svg.appendChild(g);
+	vertexEls.set(n.id, g);
+});
+
+function closure(start, next) {
+	const seen = new Set(), stack = [start];
+	while (stack.length) {
+		next.get(stack.pop()).forEach(x => {
+			if (!seen.has(x)) { seen.add(x); stack.push(x); }
+		});
+	}
+	return seen;
+}
+
+function linkTo(id) {
+	const a = el("div", {"class": "link"}, label(byId.get(id)));
+	a.addEventListener("click", () => select(id));
+	return a;
+}
+
+function describeRuntime(r) {
+	const parts = [r.called ? "called in " + (r.duration / 1e6).toFixed(3) + "ms" : "not called"];
+	if (r.consumed) parts.push("consumed " + r.consumed + (r.consumed === 1 ? " time" : " times"));
+	if (r.passive) parts.push("passive");
+	if (r.injected) parts.push("injected");
+	return parts.join(", ");
+}
+
+function select(id) {
+	const n = byId.get(id);
+	const up = closure(id, deps), down = closure(id, users);
+	svg.classList.add("focus");
+	vertexEls.forEach((g, v) => {
+		g.classList.toggle("selected", v === id);
+		g.classList.toggle("dep", up.has(v));
+		g.classList.toggle("user", down.has(v));
+	});
+	edgeEls.forEach(e => {
+		const l = e.link;
+		e.el.classList.toggle("dep", (l.from === id || up.has(l.from)) && up.has(l.to));
+		e.el.classList.toggle("user", (l.to === id || down.has(l.to)) && down.has(l.from));
+	});
+	document.querySelectorAll("#list li").forEach(li => li.classList.toggle("selected", li.dataset.id === id));
+	vertexEls.get(id).scrollIntoView({block: "center", inline: "center"});
+
+	const d = document.getElementById("details");
+	d.textContent = "";
+	d.appendChild(el("h2", {}, label(n)));
+	d.appendChild(el("div", {"class": "kind"}, n.kind + (built.has(id) ? ", built" : "")));
+	if (n.status) d.appendChild(el("div", {"class": n.status}, n.status));
+	if (n.file) d.appendChild(el("div", {}, n.file + ":" + n.line));
+	if (n.runtime) d.appendChild(el("div", {}, describeRuntime(n.runtime)));
+	d.appendChild(el("h2", {}, "Depends on"));
+	deps.get(id).forEach(x => d.appendChild(linkTo(x)));
+	d.appendChild(el("h2", {}, "Used by"));
+	users.get(id).forEach(x => d.appendChild(linkTo(x)));
+}
+
+const list = document.getElementById("list");
+nodes.slice().sort((a, b) => label(a).localeCompare(label(b))).forEach(n => {
+	const li = el("li", {"class": n.status || ""});
+	li.dataset.id = n.id;
+	li.dataset.text = [label(n), n.type, n.file].join(" ").toLowerCase();
+	li.appendChild(el("span", {"class": "kind"}, n.kind + " "));
+	li.appendChild(document.createTextNode(label(n)));
+	li.addEventListener("click", () => select(n.id));
+	list.appendChild(li);
+});
+document.getElementById("search").addEventListener("input", e => {
+	const q = e.target.value.toLowerCase();
+	list.querySelectorAll("li").forEach(li => { li.hidden = !li.dataset.text.includes(q); });
+});
+
+nodes.filter(n => n.kind !== "constructor").forEach(n => {
+	const li = el("li", {}, "");
+	li.appendChild(linkTo(n.id));
+	document.getElementById(built.has(n.id) ? "built" : "pending").appendChild(li);
+});
+
+if (data.error) {
+	const e = document.getElementById("error");
+	e.appendChild(el("h2", {"class": "root-cause"}, "Error"));
+	e.appendChild(el("pre", {}, data.error));
+}
+})();
+</script>
+</body>
+</html>
+`))
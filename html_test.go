@@ -0,0 +1,123 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// htmlData returns the graph embedded in a page written by VisualizeHTML.
+func htmlData(t *testing.T, page string) htmlGraph {
+	const prefix = "const data = "
+	i := strings.Index(page, prefix)
+	require.True(t, i >= 0, "graph data not found")
+	page = page[i+len(prefix):]
+
+	var g htmlGraph
+	require.NoError(t, json.NewDecoder(strings.NewReader(page)).Decode(&g))
+	return g
+}
+
+func TestVisualizeHTML(t *testing.T) {
+	type A struct{}
+	type B struct{}
+	type C struct{}
+
+	t.Run("built values", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() *A { return &A{} }, Names("a", "alias")))
+		require.NoError(t, c.Provide(func(*A) *B { return &B{} }))
+		require.NoError(t, c.Invoke(func(p struct {
+			In
+
+			A *A `name:"a"`
+		}) {
+		}))
+
+		var b bytes.Buffer
+		require.NoError(t, VisualizeHTML(c, &b))
+		page := b.String()
+		assert.NotContains(t, page, "http://"+"cdn", "page must not load external resources")
+		assert.NotContains(t, page, "<script src")
+
+		g := htmlData(t, page)
+		assert.Len(t, g.Vertices, 6)
+		assert.ElementsMatch(t, []string{
+			"constructor_0",
+			`*dig.A[name=a]`,
+			`*dig.A[name=alias]`,
+		}, g.Built)
+		assert.Empty(t, g.Error)
+	})
+
+	t.Run("duplicate constructors", func(t *testing.T) {
+		newA := func() *A { return &A{} }
+
+		c := New()
+		require.NoError(t, c.Provide(newA, Name("ro")))
+		require.NoError(t, c.Provide(newA, Name("rw")))
+		require.NoError(t, c.Invoke(func(p struct {
+			In
+
+			A *A `name:"ro"`
+		}) {
+		}))
+
+		var b bytes.Buffer
+		require.NoError(t, VisualizeHTML(c, &b))
+		assert.ElementsMatch(t, []string{
+			"constructor_0",
+			`*dig.A[name=ro]`,
+		}, htmlData(t, b.String()).Built)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func(*C) *A { return &A{} }))
+		err := c.Invoke(func(*A) {})
+		require.Error(t, err)
+
+		var b bytes.Buffer
+		require.NoError(t, VisualizeHTML(c, &b, VisualizeError(err)))
+
+		g := htmlData(t, b.String())
+		assert.Contains(t, g.Error, "missing type: *dig.C")
+		for _, v := range g.Vertices {
+			if v.ID == "*dig.C" {
+				assert.Equal(t, "root-cause", v.Status)
+			}
+		}
+	})
+
+	t.Run("escapes script", func(t *testing.T) {
+		var b bytes.Buffer
+		err := VisualizeHTML(New(), &b, VisualizeError(errf("</script><script>alert(1)</script>")))
+		require.NoError(t, err)
+		assert.NotContains(t, b.String(), "<script>alert")
+		assert.Equal(t, "</script><script>alert(1)</script>", htmlData(t, b.String()).Error)
+	})
+}
@@ -45,6 +45,9 @@ func (c *Container) populateValue(v reflect.Value, name string) error {
 		return nil
 	case v.Kind() == reflect.Interface && v.IsNil():
 		return nil
+	case v.Type() == _contextType:
+		// context.Context 不是注入对象
+		return nil
 	}
 
 	k := key{t: v.Type(), name: name}
@@ -77,6 +80,16 @@ func (c *Container) populateValue(v reflect.Value, name string) error {
 			if err != nil {
 				return err
 			}
+			for _, n := range c.providers[c.resolveAlias(key{t: field.Type, name: injectName})] {
+				n.injected = true
+			}
+			if c.handlesEvents() {
+				c.emit(&FieldInjected{
+					Struct: tt,
+					Field:  field.Name,
+					Key:    KeyInfo{Type: field.Type, Name: injectName},
+				})
+			}
 
 			if err := c.populateValue(fv, injectName); err != nil {
 				return err
@@ -77,6 +77,27 @@ func InspectFunc(function interface{}) *Func {
 	}
 }
 
+// InspectCaller returns information about the function that called the
+// caller of InspectCaller, skipping skip additional frames. File and Line
+// point to the call rather than to the function definition.
+func InspectCaller(skip int) *Func {
+	pc, file, line, ok := runtime.Caller(skip + 2)
+	if !ok {
+		return &Func{Name: "unknown"}
+	}
+	var name string
+	if f := runtime.FuncForPC(pc); f != nil {
+		name = f.Name()
+	}
+	pkgName, funcName := splitFuncName(name)
+	return &Func{
+		Name:    funcName,
+		Package: pkgName,
+		File:    file,
+		Line:    line,
+	}
+}
+
 const _vendor = "/vendor/"
 
 func splitFuncName(function string) (pname string, fname string) {
@@ -127,6 +127,21 @@ func TestInspectFunc(t *testing.T) {
 	}
 }
 
+func callInspectCaller(skip int) *Func {
+	return InspectCaller(skip)
+}
+
+func TestInspectCaller(t *testing.T) {
+	f := callInspectCaller(0)
+	assert.Equal(t, "go.uber.org/dig/internal/digreflect", f.Package)
+	assert.Equal(t, "TestInspectCaller", f.Name)
+	assert.True(t, strings.HasSuffix(f.File, "func_test.go"), "unexpected file %q", f.File)
+	assert.NotZero(t, f.Line)
+
+	f = func() *Func { return callInspectCaller(1) }()
+	assert.Equal(t, "TestInspectCaller", f.Name)
+}
+
 func TestSplitFunc(t *testing.T) {
 	t.Run("empty string", func(t *testing.T) {
 		pname, fname := splitFuncName("")
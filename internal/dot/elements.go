@@ -0,0 +1,263 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Kinds of Vertices.
+const (
+	KindConstructor = "constructor"
+	KindValue       = "value"
+	KindGroup       = "group"
+	KindAlias       = "alias"
+)
+
+// Kinds of Edges.
+const (
+	// EdgeDepends links a constructor to a value or group it depends on.
+	EdgeDepends = "depends"
+
+	// EdgeProvides links a constructor to a value it produces.
+	EdgeProvides = "provides"
+
+	// EdgeMember links a group to a value in the group.
+	EdgeMember = "member"
+
+	// EdgeAlias links an alias to the value it refers to.
+	EdgeAlias = "alias"
+)
+
+// Statuses of Vertices after an error was added to the graph.
+const (
+	StatusRootCause         = "root-cause"
+	StatusTransitiveFailure = "transitive-failure"
+)
+
+// Vertex is a node of the graph in a format-neutral representation.
+type Vertex struct {
+	// ID uniquely identifies the vertex. Values use the same IDs as in the
+	// DOT output.
+	ID    string `json:"id"`
+	Kind  string `json:"kind"`
+	Label string `json:"label"`
+
+	// Type, Name and Group of values, groups and aliases.
+	Type  string `json:"type,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Group string `json:"group,omitempty"`
+
+	// Location of constructors.
+	Package string `json:"package,omitempty"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+
+	// ID of the constructor producing a value, if any.
+	Constructor string `json:"constructor,omitempty"`
+
+	// Status is empty unless the vertex failed.
+	Status string `json:"status,omitempty"`
+
+	// Runtime of constructors, if requested.
+	Runtime *Runtime `json:"runtime,omitempty"`
+}
+
+// Color returns the color of the vertex for its Status.
+func (v *Vertex) Color() string {
+	switch v.Status {
+	case StatusRootCause:
+		return rootCause.Color()
+	case StatusTransitiveFailure:
+		return transitiveFailure.Color()
+	default:
+		return ""
+	}
+}
+
+// Edge is a directed edge of the graph in a format-neutral representation.
+type Edge struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Kind     string `json:"kind"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+// Elements is a format-neutral representation of the Graph, with a vertex
+// for each constructor, value, group and alias.
+type Elements struct {
+	Vertices []*Vertex `json:"nodes"`
+	Edges    []*Edge   `json:"edges"`
+
+	byID map[string]*Vertex
+}
+
+// Status returns the status of the given ErrorType for Vertices.
+func (s ErrorType) Status() string {
+	switch s {
+	case rootCause:
+		return StatusRootCause
+	case transitiveFailure:
+		return StatusTransitiveFailure
+	default:
+		return ""
+	}
+}
+
+// Elements returns the vertices and edges of the graph, in a deterministic
+// order. Failures added to the graph are recorded in the Status of the
+// vertices.
+func (dg *Graph) Elements() *Elements {
+	e := &Elements{byID: make(map[string]*Vertex)}
+
+	for i, c := range dg.Ctors {
+		id := fmt.Sprintf("constructor_%d", i)
+		e.add(&Vertex{
+			ID:      id,
+			Kind:    KindConstructor,
+			Label:   c.Name,
+			Package: c.Package,
+			File:    c.File,
+			Line:    c.Line,
+			Status:  c.ErrorType.Status(),
+			Runtime: c.Runtime,
+		})
+
+		for _, r := range c.Results {
+			v := e.value(r.String(), r.Node)
+			v.Constructor = id
+			e.Edges = append(e.Edges, &Edge{From: id, To: v.ID, Kind: EdgeProvides})
+		}
+	}
+
+	for i, c := range dg.Ctors {
+		id := fmt.Sprintf("constructor_%d", i)
+		for _, p := range c.Params {
+			v := e.value(p.String(), p.Node)
+			e.Edges = append(e.Edges, &Edge{
+				From:     id,
+				To:       v.ID,
+				Kind:     EdgeDepends,
+				Optional: p.Optional,
+			})
+		}
+		for _, g := range c.GroupParams {
+			v := e.group(g)
+			e.Edges = append(e.Edges, &Edge{From: id, To: v.ID, Kind: EdgeDepends})
+		}
+	}
+
+	for _, g := range dg.Groups {
+		gv := e.group(g)
+		for _, r := range g.Results {
+			v := e.value(r.String(), r.Node)
+			e.Edges = append(e.Edges, &Edge{From: gv.ID, To: v.ID, Kind: EdgeMember})
+		}
+	}
+
+	for _, a := range dg.Aliases {
+		av := e.value(a.String(), a.Node)
+		av.Kind = KindAlias
+		v := e.value(a.TargetString(), a.Target)
+		e.Edges = append(e.Edges, &Edge{From: av.ID, To: v.ID, Kind: EdgeAlias})
+	}
+
+	// Root causes win over transitive failures, like in the DOT output.
+	for _, r := range dg.Failed.TransitiveFailures {
+		e.value(r.String(), r.Node).Status = StatusTransitiveFailure
+	}
+	for _, r := range dg.Failed.RootCauses {
+		e.value(r.String(), r.Node).Status = StatusRootCause
+	}
+
+	return e
+}
+
+func (e *Elements) add(v *Vertex) *Vertex {
+	e.Vertices = append(e.Vertices, v)
+	e.byID[v.ID] = v
+	return v
+}
+
+// value returns the vertex of the value with the given ID, adding it if
+// necessary.
+func (e *Elements) value(id string, n *Node) *Vertex {
+	if v, ok := e.byID[id]; ok {
+		return v
+	}
+	return e.add(&Vertex{
+		ID:    id,
+		Kind:  KindValue,
+		Label: n.Type.String(),
+		Type:  n.Type.String(),
+		Name:  n.Name,
+		Group: n.Group,
+	})
+}
+
+func (e *Elements) group(g *Group) *Vertex {
+	if v, ok := e.byID[g.String()]; ok {
+		return v
+	}
+	return e.add(&Vertex{
+		ID:     g.String(),
+		Kind:   KindGroup,
+		Label:  g.Type.String(),
+		Type:   g.Type.String(),
+		Group:  g.Name,
+		Status: g.ErrorType.Status(),
+	})
+}
+
+// WriteJSON writes the Elements of the graph to w as a JSON document.
+func (dg *Graph) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(dg.Elements())
+}
+
+// ReadJSON reads Elements written by WriteJSON.
+func ReadJSON(r io.Reader) (*Elements, error) {
+	var e Elements
+	if err := json.NewDecoder(r).Decode(&e); err != nil {
+		return nil, err
+	}
+
+	e.byID = make(map[string]*Vertex, len(e.Vertices))
+	for _, v := range e.Vertices {
+		e.byID[v.ID] = v
+	}
+	for _, edge := range e.Edges {
+		for _, id := range []string{edge.From, edge.To} {
+			if _, ok := e.byID[id]; !ok {
+				return nil, fmt.Errorf("edge %v -> %v refers to unknown node %q", edge.From, edge.To, id)
+			}
+		}
+	}
+	return &e, nil
+}
+
+// Vertex returns the vertex with the given ID, or nil.
+func (e *Elements) Vertex(id string) *Vertex {
+	return e.byID[id]
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dot
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestElements(t *testing.T) {
+	type1 := reflect.TypeOf(t1{})
+	type2 := reflect.TypeOf(t2{})
+
+	n1 := &Node{Type: type1}
+	n2 := &Node{Type: type2, Name: "foo"}
+
+	dg := NewGraph()
+	dg.AddCtor(&Ctor{ID: 1, Name: "NewT1"}, []*Param{{Node: n2, Optional: true}}, []*Result{{Node: n1}})
+	dg.AddCtor(&Ctor{ID: 2, Name: "NewT2"}, nil, []*Result{{Node: n2}})
+
+	t.Run("vertices and edges", func(t *testing.T) {
+		e := dg.Elements()
+
+		var ids []string
+		for _, v := range e.Vertices {
+			ids = append(ids, v.ID)
+		}
+		assert.Equal(t, []string{"constructor_0", "dot.t1", "constructor_1", "dot.t2[name=foo]"}, ids)
+		assert.Equal(t, "constructor_1", e.Vertices[3].Constructor)
+		assert.Contains(t, e.Edges, &Edge{From: "constructor_0", To: "dot.t2[name=foo]", Kind: EdgeDepends, Optional: true})
+	})
+
+	t.Run("root causes win over transitive failures", func(t *testing.T) {
+		dg.AddMissingNodes([]*Result{{Node: n2}})
+		dg.FailNodes([]*Result{{Node: n1}, {Node: n2}}, 1)
+		e := dg.Elements()
+
+		assert.Equal(t, StatusRootCause, e.Vertices[3].Status)
+		assert.Equal(t, "red", e.Vertices[3].Color())
+		assert.Equal(t, StatusTransitiveFailure, e.Vertices[0].Status)
+		assert.Equal(t, StatusTransitiveFailure, e.Vertices[1].Status)
+		assert.Empty(t, e.Vertices[2].Status)
+	})
+}
+
+func TestMermaidQuote(t *testing.T) {
+	assert.Equal(t, `"a #quot;b#quot;"`, mermaidQuote(`a "b"`))
+}
+
+func TestWriters(t *testing.T) {
+	dg := NewGraph()
+	dg.AddCtor(&Ctor{ID: 1, Name: "NewT1"}, nil, []*Result{{Node: &Node{Type: reflect.TypeOf(t1{})}}})
+
+	var b bytes.Buffer
+	require.NoError(t, dg.WriteMermaid(&b))
+	assert.Contains(t, b.String(), `subgraph constructor_0 ["NewT1"]`)
+
+	b.Reset()
+	require.NoError(t, dg.WriteGraphML(&b))
+	assert.Contains(t, b.String(), `<node id="dot.t1">`)
+
+	b.Reset()
+	require.NoError(t, dg.WriteJSON(&b))
+	assert.Contains(t, b.String(), `"id": "constructor_0"`)
+
+	e, err := ReadJSON(&b)
+	require.NoError(t, err)
+	assert.Equal(t, dg.Elements().Vertices, e.Vertices)
+	assert.Equal(t, "NewT1", e.Vertex("constructor_0").Label)
+}
+
+func TestReadJSONErrors(t *testing.T) {
+	_, err := ReadJSON(strings.NewReader("{"))
+	assert.Error(t, err)
+
+	_, err = ReadJSON(strings.NewReader(`{"nodes": [{"id": "a"}], "edges": [{"from": "a", "to": "b"}]}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown node "b"`)
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dot
+
+import (
+	"reflect"
+	"strings"
+)
+
+// PruneUnreachable removes constructors that are not needed, directly or
+// transitively, to build any of the given roots.
+//
+// If depth is positive, only constructors at most depth steps away from the
+// roots are kept: the constructors of the roots themselves are one step
+// away, their direct dependencies two steps away, and so on. Parameters of
+// the kept constructors are still drawn if their constructors were removed.
+func (dg *Graph) PruneUnreachable(roots []*Node, depth int) {
+	producers := make(map[nodeKey][]*Ctor)
+	for _, c := range dg.Ctors {
+		for _, r := range c.Results {
+			k := r.nodeKey()
+			producers[k] = append(producers[k], c)
+		}
+	}
+	for _, a := range dg.Aliases {
+		k := a.nodeKey()
+		producers[k] = append(producers[k], producers[a.Target.nodeKey()]...)
+	}
+
+	var frontier []nodeKey
+	for _, n := range roots {
+		frontier = append(frontier, rootKey(n))
+	}
+
+	// Constructors are tracked by pointer rather than by ID so that
+	// constructors sharing an ID are told apart.
+	keep := make(map[*Ctor]struct{})
+	for step := 1; len(frontier) > 0 && (depth <= 0 || step <= depth); step++ {
+		var next []nodeKey
+		for _, k := range frontier {
+			for _, c := range producers[k] {
+				if _, ok := keep[c]; ok {
+					continue
+				}
+				keep[c] = struct{}{}
+				for _, p := range c.Params {
+					next = append(next, p.nodeKey())
+				}
+				for _, g := range c.GroupParams {
+					next = append(next, g.nodeKey())
+				}
+			}
+		}
+		frontier = next
+	}
+
+	dg.retainCtors(func(c *Ctor) bool {
+		_, ok := keep[c]
+		return ok
+	})
+}
+
+// rootKey returns the key of the value requested by the given node. Value
+// groups are requested as slices of the type of their values.
+func rootKey(n *Node) nodeKey {
+	if n.Group != "" && n.Type.Kind() == reflect.Slice {
+		return nodeKey{t: n.Type.Elem(), group: n.Group}
+	}
+	return n.nodeKey()
+}
+
+// PrunePackages removes constructors defined outside of the given packages.
+// A pattern ending with "/..." matches a package and all packages below it,
+// like for the go command. Other patterns match a single package.
+func (dg *Graph) PrunePackages(patterns []string) {
+	dg.retainCtors(func(c *Ctor) bool {
+		for _, p := range patterns {
+			if matchPackage(p, c.Package) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func matchPackage(pattern, pkg string) bool {
+	if prefix := strings.TrimSuffix(pattern, "/..."); prefix != pattern {
+		return pkg == prefix || strings.HasPrefix(pkg, prefix+"/")
+	}
+	return pkg == pattern
+}
+
+// HideGroups removes value groups from the graph, along with the grouped
+// results and parameters of constructors. Constructors that only provided
+// values to groups are removed as well.
+func (dg *Graph) HideGroups() {
+	for _, c := range dg.Ctors {
+		var results []*Result
+		for _, r := range c.Results {
+			if r.Group == "" {
+				results = append(results, r)
+			}
+		}
+		c.Results = results
+		c.GroupParams = nil
+	}
+
+	dg.Groups = nil
+	dg.groupMap = make(map[nodeKey]*Group)
+
+	dg.retainCtors(func(c *Ctor) bool {
+		return len(c.Results) > 0
+	})
+}
+
+// retainCtors removes the constructors for which keep returns false, and the
+// groups and aliases that only referred to these constructors.
+func (dg *Graph) retainCtors(keep func(*Ctor) bool) {
+	var pruned []*Ctor
+	for _, c := range dg.Ctors {
+		if keep(c) {
+			pruned = append(pruned, c)
+			continue
+		}
+		dg.pruneGroupResults(c, dg.groupMap)
+		if dg.ctorMap[c.ID] == c {
+			delete(dg.ctorMap, c.ID)
+		}
+	}
+	dg.Ctors = pruned
+	for _, c := range dg.Ctors {
+		if _, ok := dg.ctorMap[c.ID]; !ok {
+			dg.ctorMap[c.ID] = c
+		}
+	}
+
+	// Keep the groups that still have values or are consumed by a
+	// constructor.
+	used := make(map[nodeKey]struct{})
+	for _, c := range dg.Ctors {
+		for _, g := range c.GroupParams {
+			used[g.nodeKey()] = struct{}{}
+		}
+	}
+	var groups []*Group
+	for _, g := range dg.Groups {
+		k := g.nodeKey()
+		if _, ok := used[k]; ok || len(g.Results) > 0 {
+			groups = append(groups, g)
+			continue
+		}
+		delete(dg.groupMap, k)
+	}
+	dg.Groups = groups
+
+	dg.pruneAliases()
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newFilterGraph builds the graph
+//
+//   c1: t1 -> c2: t2[name=a] -> c3: t3[group=g]
+//   c4: t3[group=g] (package other)
+//   c5: t1[name=b] (package other)
+func newFilterGraph() *Graph {
+	type1 := reflect.TypeOf(t1{})
+	type2 := reflect.TypeOf(t2{})
+	type3 := reflect.TypeOf(t3{})
+
+	dg := NewGraph()
+	dg.AddCtor(&Ctor{ID: 1, Package: "example.com/app"},
+		[]*Param{{Node: &Node{Type: type2, Name: "a"}}},
+		[]*Result{{Node: &Node{Type: type1}}})
+	dg.AddCtor(&Ctor{ID: 2, Package: "example.com/app/db"},
+		[]*Param{{Node: &Node{Type: reflect.SliceOf(type3), Group: "g"}}},
+		[]*Result{{Node: &Node{Type: type2, Name: "a"}}})
+	dg.AddCtor(&Ctor{ID: 3, Package: "example.com/app/db"}, nil,
+		[]*Result{{Node: &Node{Type: type3, Group: "g"}}})
+	dg.AddCtor(&Ctor{ID: 4, Package: "example.com/other"}, nil,
+		[]*Result{{Node: &Node{Type: type3, Group: "g"}}})
+	dg.AddCtor(&Ctor{ID: 5, Package: "example.com/other"}, nil,
+		[]*Result{{Node: &Node{Type: type1, Name: "b"}}})
+	dg.AddAlias(&Alias{Node: &Node{Type: type1, Name: "c"}, Target: &Node{Type: type1, Name: "b"}})
+	return dg
+}
+
+func ctorIDs(dg *Graph) []CtorID {
+	var ids []CtorID
+	for _, c := range dg.Ctors {
+		ids = append(ids, c.ID)
+	}
+	return ids
+}
+
+func TestPruneUnreachable(t *testing.T) {
+	type1 := reflect.TypeOf(t1{})
+
+	t.Run("all dependencies", func(t *testing.T) {
+		dg := newFilterGraph()
+		dg.PruneUnreachable([]*Node{{Type: type1}}, 0)
+
+		assert.Equal(t, []CtorID{1, 2, 3, 4}, ctorIDs(dg))
+		assert.Empty(t, dg.Aliases)
+		assert.Len(t, dg.Groups, 1)
+	})
+
+	t.Run("depth", func(t *testing.T) {
+		dg := newFilterGraph()
+		dg.PruneUnreachable([]*Node{{Type: type1}}, 2)
+
+		assert.Equal(t, []CtorID{1, 2}, ctorIDs(dg))
+		assert.Len(t, dg.Groups, 1, "consumed groups must be kept")
+		assert.Empty(t, dg.Groups[0].Results)
+	})
+
+	t.Run("aliases", func(t *testing.T) {
+		dg := newFilterGraph()
+		dg.PruneUnreachable([]*Node{{Type: type1, Name: "c"}}, 0)
+
+		assert.Equal(t, []CtorID{5}, ctorIDs(dg))
+		assert.Len(t, dg.Aliases, 1)
+		assert.Empty(t, dg.Groups)
+	})
+
+	t.Run("groups", func(t *testing.T) {
+		dg := newFilterGraph()
+		dg.PruneUnreachable([]*Node{{Type: reflect.SliceOf(reflect.TypeOf(t3{})), Group: "g"}}, 0)
+
+		assert.Equal(t, []CtorID{3, 4}, ctorIDs(dg))
+	})
+
+	t.Run("constructors sharing an ID", func(t *testing.T) {
+		dg := newFilterGraph()
+		one := &Ctor{ID: 6}
+		dg.AddCtor(one, nil, []*Result{{Node: &Node{Type: type1, Name: "one"}}})
+		three := &Ctor{ID: 6}
+		dg.AddCtor(three, []*Param{{Node: &Node{Type: type1, Name: "b"}}},
+			[]*Result{{Node: &Node{Type: type1, Name: "three"}}})
+		dg.PruneUnreachable([]*Node{{Type: type1, Name: "three"}}, 0)
+
+		assert.Equal(t, []CtorID{5, 6}, ctorIDs(dg))
+		assert.Same(t, three, dg.Ctors[1])
+		assert.Same(t, three, dg.ctorMap[6])
+	})
+}
+
+func TestPrunePackages(t *testing.T) {
+	tests := []struct {
+		desc     string
+		patterns []string
+		want     []CtorID
+	}{
+		{"exact", []string{"example.com/app"}, []CtorID{1}},
+		{"subpackages", []string{"example.com/app/..."}, []CtorID{1, 2, 3}},
+		{"several patterns", []string{"example.com/app/db", "example.com/other"}, []CtorID{2, 3, 4, 5}},
+		{"no match", []string{"example.com/ap/..."}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			dg := newFilterGraph()
+			dg.PrunePackages(tt.patterns)
+			assert.Equal(t, tt.want, ctorIDs(dg))
+		})
+	}
+}
+
+func TestHideGroups(t *testing.T) {
+	dg := newFilterGraph()
+	dg.HideGroups()
+
+	assert.Empty(t, dg.Groups)
+	assert.Empty(t, dg.ctorMap[2].GroupParams)
+	assert.Equal(t, []CtorID{1, 2, 5}, ctorIDs(dg), "constructors of group values must be removed")
+	assert.Len(t, dg.ctorMap[1].Results, 1)
+}
@@ -23,6 +23,8 @@ package dot
 import (
 	"fmt"
 	"reflect"
+	"strings"
+	"time"
 )
 
 // ErrorType of a constructor or group is updated when they fail to build.
@@ -48,6 +50,58 @@ type Ctor struct {
 	GroupParams []*Group
 	Results     []*Result
 	ErrorType   ErrorType
+
+	// Runtime is set to annotate the constructor with what happened when
+	// the container ran.
+	Runtime *Runtime
+}
+
+// Runtime holds what happened to a constructor when the container ran.
+type Runtime struct {
+	// Called is set if the constructor was called.
+	Called bool `json:"called"`
+
+	// Duration is the time spent in the constructor, not including the
+	// construction of its dependencies, if it was measured.
+	Duration time.Duration `json:"duration"`
+
+	// Consumed is the number of times values produced by the constructor
+	// were passed to other functions.
+	Consumed int `json:"consumed"`
+
+	// Passive is set if the constructor was generated by PassiveProvide.
+	Passive bool `json:"passive,omitempty"`
+
+	// Injected is set if values produced by the constructor were used to
+	// populate inject-tagged fields.
+	Injected bool `json:"injected,omitempty"`
+}
+
+// String returns a short description of the runtime information.
+func (r *Runtime) String() string {
+	var parts []string
+	switch {
+	case r.Called && r.Duration > 0:
+		parts = append(parts, fmt.Sprintf("called in %v", r.Duration))
+	case r.Called:
+		parts = append(parts, "called")
+	default:
+		parts = append(parts, "not called")
+	}
+	switch r.Consumed {
+	case 0:
+	case 1:
+		parts = append(parts, "consumed once")
+	default:
+		parts = append(parts, fmt.Sprintf("consumed %d times", r.Consumed))
+	}
+	if r.Passive {
+		parts = append(parts, "passive")
+	}
+	if r.Injected {
+		parts = append(parts, "injected")
+	}
+	return strings.Join(parts, ", ")
 }
 
 // removeParam deletes the dependency on the provided result's nodeKey.
@@ -121,6 +175,15 @@ func (g *Group) removeResult(r *Result) {
 	g.Results = pruned
 }
 
+// Alias is an alias node in the graph. Aliases are not produced by
+// constructors; they refer to the Result of another constructor instead.
+type Alias struct {
+	*Node
+
+	// Target is the node that this alias refers to.
+	Target *Node
+}
+
 // Graph is the DOT-format graph in a Container.
 type Graph struct {
 	Ctors   []*Ctor
@@ -129,6 +192,8 @@ type Graph struct {
 	Groups   []*Group
 	groupMap map[nodeKey]*Group
 
+	Aliases []*Alias
+
 	consumers map[nodeKey][]*Ctor
 
 	Failed *FailedNodes
@@ -152,6 +217,10 @@ type FailedNodes struct {
 	// Groups is a collection of failed groupKeys that is populated as the graph is traversed
 	// for errors.
 	groups map[nodeKey]struct{}
+
+	// independent is set if the next failure is unrelated to the failures
+	// already recorded, and is thus a root cause as well.
+	independent bool
 }
 
 // NewGraph creates an empty graph.
@@ -219,6 +288,29 @@ func (dg *Graph) AddCtor(c *Ctor, paramList []*Param, resultList []*Result) {
 	dg.ctorMap[c.ID] = c
 }
 
+// AddAlias adds an alias referring to the result of a constructor into the
+// graph.
+func (dg *Graph) AddAlias(a *Alias) {
+	dg.Aliases = append(dg.Aliases, a)
+}
+
+// StartFailure declares that the failures added to the graph next are
+// independent of the failures already in the graph. The first of them is
+// then a root cause as well.
+func (dg *Graph) StartFailure() {
+	dg.Failed.independent = true
+}
+
+// isRootCause reports whether the failure being added to the graph is a
+// root cause.
+func (dg *Graph) isRootCause() bool {
+	if dg.Failed.independent {
+		dg.Failed.independent = false
+		return true
+	}
+	return len(dg.Failed.RootCauses) == 0
+}
+
 func (dg *Graph) failNode(r *Result, isRootCause bool) {
 	if isRootCause {
 		dg.addRootCause(r)
@@ -230,7 +322,7 @@ func (dg *Graph) failNode(r *Result, isRootCause bool) {
 // AddMissingNodes adds missing nodes to the list of failed Results in the graph.
 func (dg *Graph) AddMissingNodes(results []*Result) {
 	// The failure(s) are root causes if there are no other failures.
-	isRootCause := len(dg.Failed.RootCauses) == 0
+	isRootCause := dg.isRootCause()
 
 	for _, r := range results {
 		dg.failNode(r, isRootCause)
@@ -241,7 +333,7 @@ func (dg *Graph) AddMissingNodes(results []*Result) {
 // updates the state of the constructor with the given id accordingly.
 func (dg *Graph) FailNodes(results []*Result, id CtorID) {
 	// This failure is the root cause if there are no other failures.
-	isRootCause := len(dg.Failed.RootCauses) == 0
+	isRootCause := dg.isRootCause()
 	dg.Failed.ctors[id] = struct{}{}
 
 	for _, r := range results {
@@ -262,7 +354,7 @@ func (dg *Graph) FailNodes(results []*Result, id CtorID) {
 // with the given id accordingly.
 func (dg *Graph) FailGroupNodes(name string, t reflect.Type, id CtorID) {
 	// This failure is the root cause if there are no other failures.
-	isRootCause := len(dg.Failed.RootCauses) == 0
+	isRootCause := dg.isRootCause()
 
 	k := nodeKey{t: t, group: name}
 	group := dg.getGroup(k)
@@ -320,6 +412,26 @@ func (dg *Graph) addToGroup(r *Result, id CtorID) {
 func (dg *Graph) PruneSuccess() {
 	dg.pruneCtors(dg.Failed.ctors)
 	dg.pruneGroups(dg.Failed.groups)
+	dg.pruneAliases()
+}
+
+// pruneAliases removes aliases whose target is no longer produced by any
+// constructor in the graph.
+func (dg *Graph) pruneAliases() {
+	results := make(map[nodeKey]struct{})
+	for _, c := range dg.Ctors {
+		for _, r := range c.Results {
+			results[r.nodeKey()] = struct{}{}
+		}
+	}
+
+	var pruned []*Alias
+	for _, a := range dg.Aliases {
+		if _, ok := results[a.Target.nodeKey()]; ok {
+			pruned = append(pruned, a)
+		}
+	}
+	dg.Aliases = pruned
 }
 
 // pruneCtors removes constructors from the graph that do not have failing Results.
@@ -436,6 +548,22 @@ func (r *Result) Attributes() string {
 	}
 }
 
+// String implements fmt.Stringer for Alias.
+func (a *Alias) String() string {
+	return (&Result{Node: a.Node}).String()
+}
+
+// TargetString returns the string representation of the node that this alias
+// refers to.
+func (a *Alias) TargetString() string {
+	return (&Result{Node: a.Target}).String()
+}
+
+// Attributes composes and returns a string of the Alias node's attributes.
+func (a *Alias) Attributes() string {
+	return fmt.Sprintf(`style=dashed label=<%v<BR /><FONT POINT-SIZE="10">Alias: %v</FONT>>`, a.Type, a.Name)
+}
+
 // Attributes composes and returns a string of the Group node's attributes.
 func (g *Group) Attributes() string {
 	attr := fmt.Sprintf(`shape=diamond label=<%v<BR /><FONT POINT-SIZE="10">Group: %v</FONT>>`, g.Type, g.Name)
@@ -153,6 +153,17 @@ func TestFailNodes(t *testing.T) {
 		assert.Equal(t, []*Result{r3, r4}, dg.Failed.TransitiveFailures)
 	})
 
+	t.Run("independent failures", func(t *testing.T) {
+		dg := NewGraph()
+
+		dg.AddMissingNodes([]*Result{r1})
+		dg.StartFailure()
+		dg.AddMissingNodes([]*Result{r2})
+		dg.AddMissingNodes([]*Result{r3})
+		assert.Equal(t, []*Result{r1, r2}, dg.Failed.RootCauses)
+		assert.Equal(t, []*Result{r3}, dg.Failed.TransitiveFailures)
+	})
+
 	t.Run("fail nodes", func(t *testing.T) {
 		dg := NewGraph()
 		c0 := &Ctor{ID: 123}
@@ -372,6 +383,24 @@ func TestPruneSuccess(t *testing.T) {
 		assert.Len(t, c0.GroupParams, 2)
 		assert.Len(t, group.Results, 1)
 	})
+
+	t.Run("remove aliases of pruned constructors", func(t *testing.T) {
+		dg := NewGraph()
+		c0 := &Ctor{ID: 123}
+		c1 := &Ctor{ID: 456}
+
+		dg.AddCtor(c0, []*Param{}, []*Result{r1})
+		dg.AddCtor(c1, []*Param{}, []*Result{r2})
+		a1 := &Alias{Node: &Node{Type: type1, Name: "a1"}, Target: n1}
+		a2 := &Alias{Node: &Node{Type: type2, Name: "a2"}, Target: n2}
+		dg.AddAlias(a1)
+		dg.AddAlias(a2)
+
+		dg.FailNodes([]*Result{r1}, c0.ID)
+		dg.PruneSuccess()
+
+		assert.Equal(t, []*Alias{a1}, dg.Aliases)
+	})
 }
 
 func TestGetGroup(t *testing.T) {
@@ -431,6 +460,17 @@ func TestStringerAndAttribute(t *testing.T) {
 		assert.Equal(t, "dot.t3[group=foo]5", r3.String())
 	})
 
+	t.Run("alias stringer", func(t *testing.T) {
+		a := &Alias{Node: &Node{Type: type2, Name: "baz"}, Target: n2}
+		assert.Equal(t, "dot.t2[name=baz]", a.String())
+		assert.Equal(t, "dot.t2[name=bar]", a.TargetString())
+	})
+
+	t.Run("alias attributes", func(t *testing.T) {
+		a := &Alias{Node: &Node{Type: type2, Name: "baz"}, Target: n1}
+		assert.Equal(t, `style=dashed label=<dot.t2<BR /><FONT POINT-SIZE="10">Alias: baz</FONT>>`, a.Attributes())
+	})
+
 	t.Run("group stringer", func(t *testing.T) {
 		assert.Equal(t, "[type=dot.t1 group=group1]", g1.String())
 	})
@@ -0,0 +1,137 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dot
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+var _graphMLKeys = []graphMLKey{
+	{ID: "kind", For: "node", Name: "kind", Type: "string"},
+	{ID: "label", For: "node", Name: "label", Type: "string"},
+	{ID: "type", For: "node", Name: "type", Type: "string"},
+	{ID: "name", For: "node", Name: "name", Type: "string"},
+	{ID: "group", For: "node", Name: "group", Type: "string"},
+	{ID: "package", For: "node", Name: "package", Type: "string"},
+	{ID: "file", For: "node", Name: "file", Type: "string"},
+	{ID: "line", For: "node", Name: "line", Type: "int"},
+	{ID: "constructor", For: "node", Name: "constructor", Type: "string"},
+	{ID: "status", For: "node", Name: "status", Type: "string"},
+	{ID: "color", For: "node", Name: "color", Type: "string"},
+	{ID: "edge_kind", For: "edge", Name: "kind", Type: "string"},
+	{ID: "optional", For: "edge", Name: "optional", Type: "boolean"},
+}
+
+// WriteGraphML writes the graph to w as a GraphML document.
+func (dg *Graph) WriteGraphML(w io.Writer) error {
+	e := dg.Elements()
+
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys:  _graphMLKeys,
+		Graph: graphMLGraph{ID: "dig", EdgeDefault: "directed"},
+	}
+
+	for _, v := range e.Vertices {
+		n := graphMLNode{ID: v.ID}
+		add := func(key, value string) {
+			if value != "" {
+				n.Data = append(n.Data, graphMLData{Key: key, Value: value})
+			}
+		}
+		add("kind", v.Kind)
+		add("label", v.Label)
+		add("type", v.Type)
+		add("name", v.Name)
+		add("group", v.Group)
+		add("package", v.Package)
+		add("file", v.File)
+		if v.Line > 0 {
+			add("line", strconv.Itoa(v.Line))
+		}
+		add("constructor", v.Constructor)
+		add("status", v.Status)
+		add("color", v.Color())
+		doc.Graph.Nodes = append(doc.Graph.Nodes, n)
+	}
+
+	for _, edge := range e.Edges {
+		ge := graphMLEdge{
+			Source: edge.From,
+			Target: edge.To,
+			Data:   []graphMLData{{Key: "edge_kind", Value: edge.Kind}},
+		}
+		if edge.Optional {
+			ge.Data = append(ge.Data, graphMLData{Key: "optional", Value: "true"})
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, ge)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
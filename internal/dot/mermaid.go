@@ -0,0 +1,137 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dot
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteMermaid writes the graph to w as a Mermaid flowchart. Constructors are
+// drawn as subgraphs holding the values they produce.
+func (dg *Graph) WriteMermaid(w io.Writer) error {
+	e := dg.Elements()
+	bw := bufio.NewWriter(w)
+
+	// Mermaid IDs must be plain identifiers.
+	ids := make(map[string]string, len(e.Vertices))
+	for i, v := range e.Vertices {
+		if v.Kind == KindConstructor {
+			ids[v.ID] = v.ID
+		} else {
+			ids[v.ID] = fmt.Sprintf("node_%d", i)
+		}
+	}
+
+	fmt.Fprintln(bw, "flowchart RL")
+
+	var failed = map[string][]string{}
+	for _, v := range e.Vertices {
+		if v.Status != "" {
+			failed[v.Status] = append(failed[v.Status], ids[v.ID])
+		}
+	}
+
+	for _, c := range e.Vertices {
+		if c.Kind != KindConstructor {
+			continue
+		}
+		label := c.Label
+		if c.Package != "" {
+			label = c.Package + "." + c.Label
+		}
+		if c.Runtime != nil {
+			label += "<br/>" + c.Runtime.String()
+		}
+		fmt.Fprintf(bw, "\tsubgraph %v [%v]\n", ids[c.ID], mermaidQuote(label))
+		for _, v := range e.Vertices {
+			if v.Constructor == c.ID {
+				fmt.Fprintf(bw, "\t\t%v%v\n", ids[v.ID], mermaidShape(v))
+			}
+		}
+		fmt.Fprintln(bw, "\tend")
+	}
+
+	for _, v := range e.Vertices {
+		if v.Kind != KindConstructor && v.Constructor == "" {
+			fmt.Fprintf(bw, "\t%v%v\n", ids[v.ID], mermaidShape(v))
+		}
+	}
+
+	for _, edge := range e.Edges {
+		if edge.Kind == EdgeProvides {
+			// Drawn as part of the subgraph.
+			continue
+		}
+		arrow := "-->"
+		if edge.Optional || edge.Kind == EdgeAlias {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(bw, "\t%v %v %v\n", ids[edge.From], arrow, ids[edge.To])
+	}
+
+	for _, v := range e.Vertices {
+		if v.Runtime != nil && !v.Runtime.Called {
+			fmt.Fprintf(bw, "\tstyle %v stroke-dasharray:5 5\n", ids[v.ID])
+		}
+	}
+
+	for _, status := range []string{StatusRootCause, StatusTransitiveFailure} {
+		if len(failed[status]) == 0 {
+			continue
+		}
+		color := (&Vertex{Status: status}).Color()
+		for _, id := range failed[status] {
+			fmt.Fprintf(bw, "\tstyle %v stroke:%v,stroke-width:2px\n", id, color)
+		}
+	}
+
+	return bw.Flush()
+}
+
+func mermaidShape(v *Vertex) string {
+	label := v.Label
+	switch {
+	case v.Name != "":
+		label += "<br/>Name: " + v.Name
+	case v.Group != "":
+		label += "<br/>Group: " + v.Group
+	}
+	if v.Kind == KindAlias {
+		label = v.Label + "<br/>Alias: " + v.Name
+	}
+
+	switch v.Kind {
+	case KindGroup:
+		return "{" + mermaidQuote(label) + "}"
+	case KindAlias:
+		return "([" + mermaidQuote(label) + "])"
+	default:
+		return "[" + mermaidQuote(label) + "]"
+	}
+}
+
+// mermaidQuote quotes s as a Mermaid label.
+func mermaidQuote(s string) string {
+	return `"` + strings.Replace(s, `"`, "#quot;", -1) + `"`
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import "sort"
+
+// ProviderInfo describes a constructor provided to a container.
+type ProviderInfo struct {
+	// ID uniquely identifies the constructor in the container, even if the
+	// same function was provided more than once.
+	ID uintptr
+
+	// Location is where the constructor is defined.
+	Location Location
+
+	// Params are the values the constructor depends on, including the
+	// values requested through inject tags.
+	Params []KeyInfo
+
+	// Results are the values produced by the constructor, including the
+	// other names under which they are available.
+	Results []KeyInfo
+}
+
+// Providers returns the constructors provided to the container, in the
+// order they were provided. Constructors generated by PassiveProvide are
+// included once they were used.
+func (c *Container) Providers() []ProviderInfo {
+	infos := make([]ProviderInfo, len(c.nodes))
+	for i, n := range c.nodes {
+		infos[i] = c.providerInfo(n)
+	}
+	return infos
+}
+
+func (c *Container) providerInfo(n *node) ProviderInfo {
+	info := ProviderInfo{
+		ID:       n.seq,
+		Location: newLocation(n.location),
+	}
+	for _, k := range paramKeys(n.paramList) {
+		info.Params = append(info.Params, k.info())
+	}
+
+	results := resultKeys(n.resultList)
+	var aliases []key
+	for a, k := range c.aliases {
+		for _, r := range results {
+			if k == r {
+				aliases = append(aliases, a)
+			}
+		}
+	}
+	sort.Slice(aliases, func(i, j int) bool {
+		return aliases[i].String() < aliases[j].String()
+	})
+	for _, k := range append(results, aliases...) {
+		info.Results = append(info.Results, k.info())
+	}
+	return info
+}
+
+// Keys returns the keys of all the values and value groups provided to the
+// container, including aliases, sorted by their string representation.
+func (c *Container) Keys() []KeyInfo {
+	keys := c.knownKeys()
+	infos := make([]KeyInfo, len(keys))
+	for i, k := range keys {
+		infos[i] = k.info()
+	}
+	return infos
+}
+
+// Consumers returns the constructors which depend on the value identified by
+// the given key, in the order they were provided. Aliases of the value are
+// taken into account.
+func (c *Container) Consumers(k KeyInfo) []ProviderInfo {
+	target := c.resolveInfo(k)
+
+	var infos []ProviderInfo
+	for _, n := range c.nodes {
+		for _, pk := range requestedKeys(c, n.paramList) {
+			if pk == target {
+				infos = append(infos, c.providerInfo(n))
+				break
+			}
+		}
+	}
+	return infos
+}
+
+// IsBuilt reports whether the container already built the value identified
+// by the given key. A value group is built once the constructors of its
+// values were called.
+func (c *Container) IsBuilt(k KeyInfo) bool {
+	target := c.resolveInfo(k)
+	if target.group == "" {
+		_, ok := c.values[target]
+		return ok
+	}
+
+	providers := c.providers[target]
+	for _, n := range providers {
+		if !n.called {
+			return false
+		}
+	}
+	return len(providers) > 0
+}
+
+// resolveInfo returns the key of the given KeyInfo with aliases resolved.
+func (c *Container) resolveInfo(k KeyInfo) key {
+	if k.Group != "" {
+		return key{t: k.Type, group: k.Group}
+	}
+	return c.resolveAlias(key{t: k.Type, name: k.Name})
+}
+
+// paramKeys returns the keys of the values requested by a param list,
+// including values requested through inject tags, without duplicates.
+func paramKeys(pl paramList) []key {
+	var keys []key
+	seen := make(map[key]struct{})
+	walkParam(pl, paramVisitorFunc(func(p param) bool {
+		var k key
+		switch p := p.(type) {
+		case paramSingle:
+			k = key{name: p.Name, t: p.Type}
+		case paramGroupedSlice:
+			k = key{group: p.Group, t: p.Type.Elem()}
+		default:
+			return true
+		}
+		if _, ok := seen[k]; !ok {
+			seen[k] = struct{}{}
+			keys = append(keys, k)
+		}
+		return true
+	}))
+	return keys
+}
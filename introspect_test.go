@@ -0,0 +1,140 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntrospection(t *testing.T) {
+	type A struct{}
+	type B struct{}
+	type C struct{}
+	type injected struct {
+		B *B `inject:""`
+	}
+
+	typeA := reflect.TypeOf(&A{})
+	typeB := reflect.TypeOf(&B{})
+	typeC := reflect.TypeOf(&C{})
+
+	type params struct {
+		In
+
+		A  *A   `name:"old"`
+		Cs []*C `group:"cs"`
+	}
+
+	c := New()
+	require.NoError(t, c.Provide(func() *A { return &A{} }, Names("new", "old")))
+	require.NoError(t, c.Provide(func(params) *B { return &B{} }))
+	require.NoError(t, c.Provide(func() *C { return &C{} }, Group("cs")))
+	require.NoError(t, c.Provide(func(*injected) *A { return &A{} }))
+	require.NoError(t, c.Provide(func() *injected { return &injected{} }))
+
+	t.Run("Providers", func(t *testing.T) {
+		providers := c.Providers()
+		require.Len(t, providers, 5)
+
+		assert.Equal(t, "go.uber.org/dig", providers[0].Location.Package)
+		assert.Equal(t, "TestIntrospection.func1", providers[0].Location.Name)
+		assert.NotZero(t, providers[0].ID)
+		assert.Empty(t, providers[0].Params)
+		assert.Equal(t, []KeyInfo{
+			{Type: typeA, Name: "new"},
+			{Type: typeA, Name: "old"},
+		}, providers[0].Results)
+
+		assert.Equal(t, []KeyInfo{
+			{Type: typeA, Name: "old"},
+			{Type: reflect.TypeOf(&C{}), Group: "cs"},
+		}, providers[1].Params)
+		assert.Equal(t, []KeyInfo{{Type: typeC, Group: "cs"}}, providers[2].Results)
+		assert.Equal(t, []KeyInfo{
+			{Type: reflect.TypeOf(&injected{})},
+			{Type: typeB},
+		}, providers[3].Params, "values requested through inject tags must be included")
+	})
+
+	t.Run("Providers IDs", func(t *testing.T) {
+		newC := func() *C { return &C{} }
+
+		c := New()
+		require.NoError(t, c.Provide(newC, Name("ro")))
+		require.NoError(t, c.Provide(newC, Name("rw")))
+
+		providers := c.Providers()
+		require.Len(t, providers, 2)
+		assert.NotEqual(t, providers[0].ID, providers[1].ID,
+			"duplicate constructors must have distinct IDs")
+		assert.Equal(t, providers[1].ID, c.Clone().Providers()[1].ID,
+			"clones must keep the IDs")
+	})
+
+	t.Run("Keys", func(t *testing.T) {
+		var keys []string
+		for _, k := range c.Keys() {
+			keys = append(keys, k.String())
+		}
+		assert.Equal(t, []string{
+			"*dig.A",
+			`*dig.A[name="new"]`,
+			`*dig.A[name="old"]`,
+			"*dig.B",
+			`*dig.C[group="cs"]`,
+			"*dig.injected",
+		}, keys)
+	})
+
+	t.Run("Consumers", func(t *testing.T) {
+		consumers := c.Consumers(KeyInfo{Type: typeA, Name: "new"})
+		require.Len(t, consumers, 1, "aliases must be resolved")
+		assert.Equal(t, []KeyInfo{{Type: typeB}}, consumers[0].Results)
+
+		consumers = c.Consumers(KeyInfo{Type: typeC, Group: "cs"})
+		require.Len(t, consumers, 1)
+		assert.Equal(t, []KeyInfo{{Type: typeB}}, consumers[0].Results)
+
+		assert.Empty(t, c.Consumers(KeyInfo{Type: typeA}))
+		consumers = c.Consumers(KeyInfo{Type: typeB})
+		require.Len(t, consumers, 1, "inject tags must be followed")
+		assert.Equal(t, []KeyInfo{{Type: typeA}}, consumers[0].Results)
+	})
+
+	t.Run("IsBuilt", func(t *testing.T) {
+		c := c.Clone()
+		old := KeyInfo{Type: typeA, Name: "old"}
+		cs := KeyInfo{Type: typeC, Group: "cs"}
+
+		assert.False(t, c.IsBuilt(old))
+		assert.False(t, c.IsBuilt(cs))
+		require.NoError(t, c.Invoke(func(*B) {}))
+		assert.True(t, c.IsBuilt(old))
+		assert.True(t, c.IsBuilt(KeyInfo{Type: typeA, Name: "new"}))
+		assert.True(t, c.IsBuilt(cs))
+		assert.False(t, c.IsBuilt(KeyInfo{Type: typeA}))
+		assert.False(t, c.IsBuilt(KeyInfo{Type: typeB, Name: "missing"}))
+	})
+}
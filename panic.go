@@ -0,0 +1,98 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"go.uber.org/dig/internal/digreflect"
+)
+
+// RecoverPanics is an Option which, when set to true, recovers panics in
+// constructors and in functions passed to Invoke. A panic is then returned
+// as an error naming the function that panicked, with the panic value and
+// the stack of the panic in its %+v form. RootCause returns this error, and
+// Visualize draws the constructor that panicked as the root cause of the
+// failure.
+//
+// Without this option, panics propagate to the caller of Invoke as before,
+// unless SafeMode is used.
+func RecoverPanics(recover bool) Option {
+	return optionFunc(func(c *Container) {
+		c.recoverPanics = recover
+	})
+}
+
+// SafeMode is an Option that turns on the behaviors that protect an
+// application from failures of the functions given to the container. In
+// safe mode, panics are recovered as with RecoverPanics(true).
+//
+// Options given after SafeMode take precedence over it, so
+// RecoverPanics(false) turns panic recovery back off.
+func SafeMode() Option {
+	return optionFunc(func(c *Container) {
+		c.recoverPanics = true
+	})
+}
+
+func (c *Container) recoversPanics() bool {
+	return c.recoverPanics
+}
+
+// errConstructorPanicked is returned when a constructor or a function passed
+// to Invoke panicked and the container recovers panics.
+type errConstructorPanicked struct {
+	Func  *digreflect.Func
+	Panic interface{}
+	Stack []byte
+}
+
+// Unwrap returns the panic value if it is an error, so that errors.Is and
+// errors.As match errors passed to panic.
+func (e errConstructorPanicked) Unwrap() error {
+	err, _ := e.Panic.(error)
+	return err
+}
+
+func (e errConstructorPanicked) Error() string { return fmt.Sprint(e) }
+
+// Format prints the function and the panic value. With %+v, it also prints
+// the stack of the panic.
+func (e errConstructorPanicked) Format(w fmt.State, c rune) {
+	if w.Flag('+') && c == 'v' {
+		fmt.Fprintf(w, "panic in function %+v: %v\n%s", e.Func, e.Panic, e.Stack)
+		return
+	}
+	fmt.Fprintf(w, "panic in function %v: %v", e.Func, e.Panic)
+}
+
+// recoverPanic turns a panic of the function f into an
+// errConstructorPanicked stored in err. It must be deferred.
+func recoverPanic(f *digreflect.Func, err *error) {
+	if r := recover(); r != nil {
+		*err = errConstructorPanicked{
+			Func:  f,
+			Panic: r,
+			Stack: debug.Stack(),
+		}
+	}
+}
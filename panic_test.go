@@ -0,0 +1,98 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoverPanics(t *testing.T) {
+	type A struct{}
+	type B struct{}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() *A { panic("great sadness") }))
+		assert.PanicsWithValue(t, "great sadness", func() {
+			c.Invoke(func(*A) {})
+		})
+	})
+
+	t.Run("constructor", func(t *testing.T) {
+		c := New(SafeMode())
+		require.NoError(t, c.Provide(func() *A { panic("great sadness") }))
+		require.NoError(t, c.Provide(func(*A) *B { return &B{} }))
+
+		err := c.Invoke(func(*B) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "panic in function")
+		assert.Contains(t, err.Error(), "TestRecoverPanics.func2.1")
+		assert.Contains(t, err.Error(), "great sadness")
+
+		var panicked errConstructorPanicked
+		require.True(t, errors.As(err, &panicked))
+		assert.Equal(t, "great sadness", panicked.Panic)
+		assert.Equal(t, "TestRecoverPanics.func2.1", panicked.Func.Name)
+		assert.Equal(t, panicked, RootCause(err))
+		assert.Contains(t, fmt.Sprintf("%+v", err), "panic_test.go", "%+v must include the stack")
+		assert.NotContains(t, err.Error(), "goroutine")
+
+		report := ErrorReport(err)
+		for report.Cause != nil {
+			report = report.Cause
+		}
+		assert.Equal(t, KindConstructorPanicked, report.Kind)
+		assert.Equal(t, "TestRecoverPanics.func2.1", report.Func.Name)
+
+		var b bytes.Buffer
+		require.NoError(t, Visualize(c, &b, VisualizeError(err)))
+		assert.Regexp(t, `label="TestRecoverPanics.func2.1"\];\s+color=red;`, b.String(),
+			"the constructor that panicked must be the root cause")
+		assert.Regexp(t, `label="TestRecoverPanics.func2.2"\];\s+color=orange;`, b.String())
+	})
+
+	t.Run("invoke", func(t *testing.T) {
+		sadness := errors.New("great sadness")
+		c := New(RecoverPanics(true))
+		err := c.Invoke(func() { panic(sadness) })
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, sadness))
+		assert.Contains(t, err.Error(), "TestRecoverPanics.func3.1")
+	})
+
+	t.Run("turned off after safe mode", func(t *testing.T) {
+		c := New(SafeMode(), RecoverPanics(false))
+		assert.Panics(t, func() {
+			c.Invoke(func() { panic("great sadness") })
+		})
+	})
+
+	t.Run("clone", func(t *testing.T) {
+		c := New(SafeMode()).Clone()
+		assert.Error(t, c.Invoke(func() { panic("great sadness") }))
+	})
+}
@@ -114,6 +114,13 @@ func (f paramVisitorFunc) Visit(p param) paramVisitor {
 //
 // This is very similar to how go/ast.Walk works.
 func walkParam(p param, v paramVisitor) {
+	walkParamInjected(p, v, make(map[reflect.Type]struct{}))
+}
+
+// walkParamInjected is walkParam with a record of the struct types whose
+// inject-tagged fields have already been walked. Types referencing each
+// other through inject tags would otherwise be walked forever.
+func walkParamInjected(p param, v paramVisitor, injected map[reflect.Type]struct{}) {
 	v = v.Visit(p)
 	if v == nil {
 		return
@@ -121,24 +128,31 @@ func walkParam(p param, v paramVisitor) {
 
 	switch par := p.(type) {
 	case paramSingle:
-		if t, ok := isStructType(par.Type); ok {
-			n := t.NumField()
-			for i := 0; i < n; i++ {
-				field := t.Field(i)
-				if name, ok := field.Tag.Lookup(_injectTag); ok {
-					walkParam(paramSingle{Type: field.Type, Name: name}, v)
-				}
+		t, ok := isStructType(par.Type)
+		if !ok {
+			break
+		}
+		if _, ok := injected[t]; ok {
+			break
+		}
+		injected[t] = struct{}{}
+
+		n := t.NumField()
+		for i := 0; i < n; i++ {
+			field := t.Field(i)
+			if name, ok := field.Tag.Lookup(_injectTag); ok {
+				walkParamInjected(paramSingle{Type: field.Type, Name: name}, v, injected)
 			}
 		}
 	case paramGroupedSlice:
 		// No sub-results
 	case paramObject:
 		for _, f := range par.Fields {
-			walkParam(f.Param, v)
+			walkParamInjected(f.Param, v, injected)
 		}
 	case paramList:
 		for _, p := range par.Params {
-			walkParam(p, v)
+			walkParamInjected(p, v, injected)
 		}
 	default:
 		panic(fmt.Sprintf(
@@ -239,7 +253,13 @@ func (ps paramSingle) DotParam() []*dot.Param {
 }
 
 func (ps paramSingle) Build(c containerStore) (reflect.Value, error) {
+	if isInvokeContext(c, ps) {
+		ctx := c.invokeContext()
+		return reflect.ValueOf(&ctx).Elem(), nil
+	}
+
 	if v, ok := c.getValue(ps.Name, ps.Type); ok {
+		ps.consume(c)
 		return v, nil
 	}
 
@@ -277,9 +297,17 @@ func (ps paramSingle) Build(c containerStore) (reflect.Value, error) {
 	// If we get here, it's impossible for the value to be absent from the
 	// container.
 	v, _ := c.getValue(ps.Name, ps.Type)
+	ps.consume(c)
 	return v, nil
 }
 
+// consume records that the value requested by this param was consumed.
+func (ps paramSingle) consume(c containerStore) {
+	for _, n := range c.getValueProviders(ps.Name, ps.Type) {
+		n.Consumed()
+	}
+}
+
 // paramObject is a dig.In struct where each field is another param.
 //
 // This object is not expected in the graph as-is.
@@ -487,6 +515,10 @@ func (pt paramGroupedSlice) Build(c containerStore) (reflect.Value, error) {
 		}
 	}
 
+	for _, n := range c.getGroupProviders(pt.Group, pt.Type.Elem()) {
+		n.Consumed()
+	}
+
 	items := c.getValueGroup(pt.Group, pt.Type.Elem())
 
 	result := reflect.MakeSlice(pt.Type, len(items), len(items))
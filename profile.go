@@ -0,0 +1,214 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"go.uber.org/dig/internal/digreflect"
+)
+
+// ProfileConstructors is an Option that measures the time spent in every
+// constructor call and records the functions passed to Invoke and the
+// constructors whose calls led to it. Container.Profile reports these times
+// and callers, which WriteFolded exports as stacks.
+//
+// Without this option, constructor calls are not timed.
+func ProfileConstructors() Option {
+	return optionFunc(func(c *Container) {
+		c.callStack = new(callStack)
+	})
+}
+
+// callStack is the stack of functions being called by a container.
+type callStack struct {
+	funcs []*digreflect.Func
+}
+
+// push adds a function to the stack and returns the functions that were
+// already on it, outermost first.
+func (s *callStack) push(f *digreflect.Func) []*digreflect.Func {
+	callers := append([]*digreflect.Func(nil), s.funcs...)
+	s.funcs = append(s.funcs, f)
+	return callers
+}
+
+func (s *callStack) pop() {
+	s.funcs = s.funcs[:len(s.funcs)-1]
+}
+
+// ConstructorProfile describes the call to a constructor.
+type ConstructorProfile struct {
+	// Location is where the constructor is defined.
+	Location Location
+
+	// Self is the time spent in the constructor itself.
+	Self time.Duration
+
+	// Total is Self plus the time spent building the dependencies of the
+	// constructor that were not built yet when it was needed.
+	Total time.Duration
+
+	// Callers are the functions passed to Invoke and the constructors whose
+	// calls led to this one, outermost first.
+	Callers []Location
+}
+
+// PackageProfile is the time spent in the constructors of a package.
+type PackageProfile struct {
+	// Package is the import path of the package.
+	Package string
+
+	// Constructors is the number of constructors of the package that were
+	// called.
+	Constructors int
+
+	// Self is the time spent in these constructors, not including the time
+	// spent building their dependencies, even those from the same package.
+	Self time.Duration
+}
+
+// Profile describes the time spent in the constructors called by a
+// container.
+type Profile struct {
+	// Constructors are the constructors that were called, slowest first.
+	Constructors []ConstructorProfile
+}
+
+// Profile returns the time spent in the constructors the container called so
+// far. Times are only measured with the ProfileConstructors option and are
+// zero otherwise.
+func (c *Container) Profile() Profile {
+	var p Profile
+	for _, n := range c.nodes {
+		if !n.called {
+			continue
+		}
+		cp := ConstructorProfile{
+			Location: newLocation(n.location),
+			Self:     n.duration,
+			Total:    n.total,
+		}
+		for _, f := range n.callers {
+			cp.Callers = append(cp.Callers, newLocation(f))
+		}
+		p.Constructors = append(p.Constructors, cp)
+	}
+	sort.SliceStable(p.Constructors, func(i, j int) bool {
+		return p.Constructors[i].Self > p.Constructors[j].Self
+	})
+	return p
+}
+
+// Total returns the time spent in all the constructors.
+func (p Profile) Total() time.Duration {
+	var total time.Duration
+	for _, cp := range p.Constructors {
+		total += cp.Self
+	}
+	return total
+}
+
+// Packages returns the time spent in the constructors of each package,
+// slowest first.
+func (p Profile) Packages() []PackageProfile {
+	byPackage := make(map[string]*PackageProfile)
+	var pkgs []*PackageProfile
+	for _, cp := range p.Constructors {
+		pp, ok := byPackage[cp.Location.Package]
+		if !ok {
+			pp = &PackageProfile{Package: cp.Location.Package}
+			byPackage[cp.Location.Package] = pp
+			pkgs = append(pkgs, pp)
+		}
+		pp.Constructors++
+		pp.Self += cp.Self
+	}
+
+	sort.SliceStable(pkgs, func(i, j int) bool {
+		if pkgs[i].Self != pkgs[j].Self {
+			return pkgs[i].Self > pkgs[j].Self
+		}
+		return pkgs[i].Package < pkgs[j].Package
+	})
+	profiles := make([]PackageProfile, len(pkgs))
+	for i, pp := range pkgs {
+		profiles[i] = *pp
+	}
+	return profiles
+}
+
+// WriteTable writes the constructors of the profile to w as a table,
+// slowest first.
+//
+//   SELF    TOTAL   CONSTRUCTOR
+//   3.2s    3.5s    "go.uber.org/foo".NewDB (db.go:42)
+//   300ms   300ms   "go.uber.org/foo".NewConfig (config.go:12)
+func (p Profile) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "SELF\tTOTAL\tCONSTRUCTOR")
+	for _, cp := range p.Constructors {
+		fmt.Fprintf(tw, "%v\t%v\t%v\n", cp.Self, cp.Total, cp.Location)
+	}
+	return tw.Flush()
+}
+
+// WriteFolded writes the profile to w in the folded stacks format read by
+// flame graph tools such as flamegraph.pl and speedscope: one line per
+// stack of callers and constructor, followed by the time spent in the
+// constructor itself in microseconds.
+//
+//   go.uber.org/foo.main.func1;go.uber.org/foo.NewServer;go.uber.org/foo.NewDB 3200000
+func (p Profile) WriteFolded(w io.Writer) error {
+	samples := make(map[string]time.Duration)
+	var stacks []string
+	for _, cp := range p.Constructors {
+		frames := make([]string, 0, len(cp.Callers)+1)
+		for _, l := range append(cp.Callers, cp.Location) {
+			frames = append(frames, foldedFrame(l))
+		}
+
+		stack := strings.Join(frames, ";")
+		if _, ok := samples[stack]; !ok {
+			stacks = append(stacks, stack)
+		}
+		samples[stack] += cp.Self
+	}
+	sort.Strings(stacks)
+
+	bw := bufio.NewWriter(w)
+	for _, stack := range stacks {
+		fmt.Fprintf(bw, "%v %d\n", stack, samples[stack].Microseconds())
+	}
+	return bw.Flush()
+}
+
+// foldedFrame names a function in a folded stack, where semicolons separate
+// frames and spaces separate the stack from its value.
+func foldedFrame(l Location) string {
+	return strings.NewReplacer(";", "_", " ", "_").Replace(l.Package + "." + l.Name)
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfile(t *testing.T) {
+	type A struct{}
+	type B struct{}
+
+	newContainer := func(t *testing.T, opts ...Option) *Container {
+		c := New(opts...)
+		require.NoError(t, c.Provide(func() *A {
+			time.Sleep(10 * time.Millisecond)
+			return &A{}
+		}))
+		require.NoError(t, c.Provide(func(*A) *B { return &B{} }))
+		require.NoError(t, c.Provide(func() string { return "unused" }))
+		require.NoError(t, c.Invoke(func(*B) {}))
+		return c
+	}
+
+	t.Run("times constructors", func(t *testing.T) {
+		p := newContainer(t, ProfileConstructors()).Profile()
+		require.Len(t, p.Constructors, 2, "only called constructors are profiled")
+
+		a, b := p.Constructors[0], p.Constructors[1]
+		assert.Equal(t, "TestProfile.func1.1", a.Location.Name)
+		assert.Equal(t, "TestProfile.func1.2", b.Location.Name)
+		assert.True(t, a.Self >= 10*time.Millisecond, "A took %v", a.Self)
+		assert.True(t, b.Self < a.Self, "B took %v", b.Self)
+		assert.True(t, b.Total >= a.Total, "B took %v with its dependencies", b.Total)
+		assert.Equal(t, a.Self+b.Self, p.Total())
+	})
+
+	t.Run("not timed without ProfileConstructors", func(t *testing.T) {
+		p := newContainer(t).Profile()
+		require.Len(t, p.Constructors, 2)
+		for _, cp := range p.Constructors {
+			assert.Zero(t, cp.Self)
+			assert.Zero(t, cp.Total)
+			assert.Empty(t, cp.Callers)
+		}
+	})
+
+	t.Run("records callers", func(t *testing.T) {
+		p := newContainer(t, ProfileConstructors()).Profile()
+		require.Len(t, p.Constructors, 2)
+
+		var callers []string
+		for _, l := range p.Constructors[0].Callers {
+			callers = append(callers, l.Name)
+		}
+		assert.Equal(t, []string{"TestProfile.func1.4", "TestProfile.func1.2"}, callers)
+
+		require.Len(t, p.Constructors[1].Callers, 1)
+		assert.Equal(t, "TestProfile.func1.4", p.Constructors[1].Callers[0].Name)
+	})
+
+	t.Run("clone", func(t *testing.T) {
+		c := newContainer(t, ProfileConstructors())
+		assert.Empty(t, c.Clone().Profile().Constructors)
+		assert.Len(t, c.Clone(CloneValues()).Profile().Constructors, 2)
+	})
+}
+
+func TestProfileExports(t *testing.T) {
+	p := Profile{Constructors: []ConstructorProfile{
+		{
+			Location: Location{Package: "foo", Name: "NewDB", File: "db.go", Line: 42},
+			Self:     3 * time.Second,
+			Total:    3500 * time.Millisecond,
+			Callers: []Location{
+				{Package: "foo", Name: "main.func1"},
+				{Package: "foo", Name: "NewServer"},
+			},
+		},
+		{
+			Location: Location{Package: "bar", Name: "NewConfig", File: "config.go", Line: 12},
+			Self:     500 * time.Millisecond,
+			Total:    500 * time.Millisecond,
+			Callers: []Location{
+				{Package: "foo", Name: "main.func1"},
+				{Package: "foo", Name: "NewServer"},
+				{Package: "foo", Name: "NewDB"},
+			},
+		},
+		{
+			Location: Location{Package: "foo", Name: "NewServer", File: "server.go", Line: 7},
+			Self:     time.Millisecond,
+			Total:    3501 * time.Millisecond,
+			Callers:  []Location{{Package: "foo", Name: "main.func1"}},
+		},
+	}}
+
+	t.Run("table", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, p.WriteTable(&buf))
+		assert.Equal(t, `SELF   TOTAL   CONSTRUCTOR
+3s     3.5s    "foo".NewDB (db.go:42)
+500ms  500ms   "bar".NewConfig (config.go:12)
+1ms    3.501s  "foo".NewServer (server.go:7)
+`, buf.String())
+	})
+
+	t.Run("folded", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, p.WriteFolded(&buf))
+		assert.Equal(t, `foo.main.func1;foo.NewServer 1000
+foo.main.func1;foo.NewServer;foo.NewDB 3000000
+foo.main.func1;foo.NewServer;foo.NewDB;bar.NewConfig 500000
+`, buf.String())
+	})
+
+	t.Run("packages", func(t *testing.T) {
+		assert.Equal(t, []PackageProfile{
+			{Package: "foo", Constructors: 2, Self: 3001 * time.Millisecond},
+			{Package: "bar", Constructors: 1, Self: 500 * time.Millisecond},
+		}, p.Packages())
+		assert.Equal(t, 3501*time.Millisecond, p.Total())
+	})
+}
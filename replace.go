@@ -0,0 +1,268 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"errors"
+	"reflect"
+)
+
+// Force is a ProvideOption for Replace that allows replacing constructors
+// whose values were already instantiated. The instantiated values are
+// discarded from the container and built again by the new constructor when
+// they are next requested. Values that were already handed to other
+// constructors or invoked functions are not affected.
+//
+// This option cannot be provided to Provide.
+func Force() ProvideOption {
+	return provideOptionFunc(func(opts *provideOptions) {
+		opts.Force = true
+	})
+}
+
+// Replace replaces the constructors that produce the same values as the given
+// constructor with it. This is intended for tests which need to swap a
+// production constructor for a fake after the production constructors were
+// provided.
+//
+//   c.Provide(NewDB)
+//   c.Replace(func() *sql.DB { return fakeDB })
+//
+// Replace accepts the same constructors and options as Provide. Every value
+// produced by a replaced constructor must also be produced by the new
+// constructor. If no constructor produces the values yet, Replace behaves
+// like Provide. Aliases of the replaced values, whether declared with Names
+// or added with Container.Alias, are kept.
+//
+// Replace fails if a replaced constructor was already called, unless the
+// Force option is provided.
+func (c *Container) Replace(constructor interface{}, opts ...ProvideOption) error {
+	ctype := reflect.TypeOf(constructor)
+	if ctype == nil {
+		return errors.New("can't provide an untyped nil")
+	}
+	if ctype.Kind() != reflect.Func {
+		return errf("must provide constructor function, got %v (type %v)", constructor, ctype)
+	}
+
+	var options provideOptions
+	for _, o := range opts {
+		o.applyProvideOption(&options)
+	}
+	force := options.Force
+	options.Force = false
+	if err := options.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.replace(constructor, options, force); err != nil {
+		return errProvide{
+			Func:   options.location(constructor),
+			Reason: err,
+		}
+	}
+	return nil
+}
+
+func (c *Container) replace(ctor interface{}, opts provideOptions, force bool) error {
+	n, err := newNode(
+		ctor,
+		nodeOptions{
+			ResultName:    opts.Name,
+			ResultGroup:   opts.Group,
+			ResultAliases: opts.Aliases,
+			Timeout:       opts.Timeout,
+			Location:      opts.Location,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	// Constructors are replaced if they provide any of the single values of
+	// the new constructor. Value groups accept any number of constructors so
+	// they never cause a replacement on their own.
+	newKeys := make(map[key]struct{})
+	replaced := make(map[*node]struct{})
+	for _, k := range resultKeys(n.resultList) {
+		newKeys[k] = struct{}{}
+		if k.group != "" {
+			continue
+		}
+		for _, old := range c.providers[k] {
+			replaced[old] = struct{}{}
+		}
+	}
+
+	for old := range replaced {
+		for _, k := range resultKeys(old.resultList) {
+			if _, ok := newKeys[k]; !ok {
+				return errf(
+					"cannot replace %v", old.location,
+					"%v is not provided by the new constructor", k)
+			}
+			if !old.called {
+				continue
+			}
+			if k.group != "" {
+				return errf(
+					"cannot replace %v", old.location,
+					"value group %v was already built", k)
+			}
+			if !force {
+				return errf(
+					"cannot replace %v", old.location,
+					"%v was already built, use dig.Force() to replace it anyway", k)
+			}
+		}
+	}
+
+	aliases, undo := c.removeNodes(replaced)
+
+	// The aliases declared by the replaced constructors refer to values that
+	// the new constructor provides as well, so they are kept unless the new
+	// constructor provides or declares them itself. If the new constructor
+	// is invalid, provideNode reports why below.
+	if keys, declared, err := c.findAndValidateResults(n); err == nil {
+		for a, k := range aliases {
+			_, provided := keys[a]
+			_, redeclared := declared[a]
+			if !provided && !redeclared {
+				c.aliases[a] = k
+			}
+		}
+	}
+
+	if err := c.provideNode(ctor, n); err != nil {
+		// provideNode may have registered the new node for some of its
+		// keys before failing.
+		c.removeNodes(map[*node]struct{}{n: {}})
+		undo()
+		return err
+	}
+
+	// Values that were populated by the replaced constructors may still be
+	// referenced by the inject graph.
+	for old := range replaced {
+		if old.called {
+			c.graph = newInjectGraph()
+			break
+		}
+	}
+	return nil
+}
+
+// removeNodes removes the given nodes, their values and the aliases they
+// declared from the container. It returns the removed aliases, and a
+// function that restores everything it removed.
+func (c *Container) removeNodes(nodes map[*node]struct{}) (aliases map[key]key, undo func()) {
+	var (
+		oldNodes     = c.nodes
+		oldProviders = make(map[key][]*node)
+		oldValues    = make(map[key]reflect.Value)
+		oldAliases   = make(map[key]key)
+	)
+
+	var kept []*node
+	for _, n := range c.nodes {
+		if _, ok := nodes[n]; !ok {
+			kept = append(kept, n)
+		}
+	}
+	c.nodes = kept
+
+	for n := range nodes {
+		for _, k := range resultKeys(n.resultList) {
+			if _, ok := oldProviders[k]; !ok {
+				oldProviders[k] = c.providers[k]
+			}
+			var ps []*node
+			for _, p := range c.providers[k] {
+				if p != n {
+					ps = append(ps, p)
+				}
+			}
+			if len(ps) > 0 {
+				c.providers[k] = ps
+			} else {
+				delete(c.providers, k)
+			}
+
+			if v, ok := c.values[k]; ok {
+				oldValues[k] = v
+				delete(c.values, k)
+			}
+
+			if k.group != "" {
+				continue
+			}
+			for _, name := range n.aliases {
+				a := key{name: name, t: k.t}
+				if target, ok := c.aliases[a]; ok && target == k {
+					oldAliases[a] = target
+					delete(c.aliases, a)
+				}
+			}
+		}
+	}
+
+	return oldAliases, func() {
+		c.nodes = oldNodes
+		for k, ps := range oldProviders {
+			if len(ps) > 0 {
+				c.providers[k] = ps
+			} else {
+				delete(c.providers, k)
+			}
+		}
+		for k, v := range oldValues {
+			c.values[k] = v
+		}
+		for a, k := range oldAliases {
+			c.aliases[a] = k
+		}
+	}
+}
+
+// resultKeys returns the keys of all values produced by the given result.
+func resultKeys(r result) []key {
+	var keys []key
+	walkResult(r, keyCollector{keys: &keys})
+	return keys
+}
+
+// keyCollector is a resultVisitor that collects the keys of visited results.
+type keyCollector struct {
+	keys *[]key
+}
+
+func (kc keyCollector) AnnotateWithField(resultObjectField) resultVisitor { return kc }
+func (kc keyCollector) AnnotateWithPosition(int) resultVisitor            { return kc }
+
+func (kc keyCollector) Visit(res result) resultVisitor {
+	switch r := res.(type) {
+	case resultSingle:
+		*kc.keys = append(*kc.keys, key{name: r.Name, t: r.Type})
+	case resultGrouped:
+		*kc.keys = append(*kc.keys, key{group: r.Group, t: r.Type})
+	}
+	return kc
+}
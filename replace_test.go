@@ -0,0 +1,190 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplace(t *testing.T) {
+	type A struct{ name string }
+	type B struct{ a *A }
+
+	newB := func(a *A) *B { return &B{a: a} }
+
+	t.Run("replaces existing constructor", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() *A { return &A{name: "real"} }))
+		require.NoError(t, c.Provide(newB))
+		require.NoError(t, c.Replace(func() *A { return &A{name: "fake"} }))
+
+		require.NoError(t, c.Invoke(func(b *B) {
+			assert.Equal(t, "fake", b.a.name)
+		}))
+		assert.Len(t, c.nodes, 2)
+		assert.Len(t, c.providers[key{t: reflect.TypeOf(&A{})}], 1)
+	})
+
+	t.Run("behaves like Provide without existing constructors", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Replace(func() *A { return &A{name: "fake"} }, Name("a")))
+
+		type param struct {
+			In
+
+			A *A `name:"a"`
+		}
+		require.NoError(t, c.Invoke(func(p param) {
+			assert.Equal(t, "fake", p.A.name)
+		}))
+	})
+
+	t.Run("keeps other named values", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() *A { return &A{name: "ro"} }, Name("ro")))
+		require.NoError(t, c.Provide(func() *A { return &A{name: "rw"} }, Name("rw")))
+		require.NoError(t, c.Replace(func() *A { return &A{name: "fake"} }, Name("rw")))
+
+		type param struct {
+			In
+
+			RO *A `name:"ro"`
+			RW *A `name:"rw"`
+		}
+		require.NoError(t, c.Invoke(func(p param) {
+			assert.Equal(t, "ro", p.RO.name)
+			assert.Equal(t, "fake", p.RW.name)
+		}))
+	})
+
+	t.Run("replaces aliases of the old constructor", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() *A { return &A{name: "real"} }, Names("new", "old")))
+		require.NoError(t, c.Replace(func() *A { return &A{name: "fake"} }, Names("new", "old")))
+
+		type param struct {
+			In
+
+			A *A `name:"old"`
+		}
+		require.NoError(t, c.Invoke(func(p param) {
+			assert.Equal(t, "fake", p.A.name)
+		}))
+	})
+
+	t.Run("keeps aliases of the old constructor", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() *A { return &A{name: "real"} }, Names("db", "database")))
+		require.NoError(t, c.Replace(func() *A { return &A{name: "fake"} }, Name("db")))
+
+		type param struct {
+			In
+
+			A *A `name:"database"`
+		}
+		require.NoError(t, c.Invoke(func(p param) {
+			assert.Equal(t, "fake", p.A.name)
+		}))
+	})
+
+	t.Run("kept aliases are checked for cycles", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() *A { return &A{name: "real"} }, Names("db", "database")))
+
+		type param struct {
+			In
+
+			A *A `name:"database"`
+		}
+		err := c.Replace(func(p param) *A { return p.A }, Name("db"))
+		require.Error(t, err)
+		assert.True(t, IsCycleDetected(err))
+
+		require.NoError(t, c.Invoke(func(p param) {
+			assert.Equal(t, "real", p.A.name)
+		}))
+	})
+
+	t.Run("fails for instantiated values", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() *A { return &A{name: "real"} }))
+		require.NoError(t, c.Invoke(func(*A) {}))
+
+		err := c.Replace(func() *A { return &A{name: "fake"} })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "*dig.A was already built, use dig.Force() to replace it anyway")
+
+		require.NoError(t, c.Invoke(func(a *A) {
+			assert.Equal(t, "real", a.name, "container must be left untouched")
+		}))
+	})
+
+	t.Run("forces replacement of instantiated values", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() *A { return &A{name: "real"} }))
+		require.NoError(t, c.Invoke(func(*A) {}))
+		require.NoError(t, c.Replace(func() *A { return &A{name: "fake"} }, Force()))
+
+		require.NoError(t, c.Invoke(func(a *A) {
+			assert.Equal(t, "fake", a.name)
+		}))
+	})
+
+	t.Run("new constructor must provide all replaced values", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() (*A, *B) { return &A{}, &B{} }))
+
+		err := c.Replace(func() *A { return &A{name: "fake"} })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "*dig.B is not provided by the new constructor")
+	})
+
+	t.Run("cycles are rolled back", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() *A { return &A{name: "real"} }))
+		require.NoError(t, c.Provide(newB))
+
+		err := c.Replace(func(*B) *A { return &A{name: "fake"} })
+		require.Error(t, err)
+		assert.True(t, IsCycleDetected(err))
+
+		require.NoError(t, c.Invoke(func(b *B) {
+			assert.Equal(t, "real", b.a.name)
+		}))
+	})
+
+	t.Run("Force cannot be used with Provide", func(t *testing.T) {
+		c := New()
+		err := c.Provide(func() *A { return &A{} }, Force())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "dig.Force() may only be used with Replace")
+	})
+
+	t.Run("invalid constructors", func(t *testing.T) {
+		c := New()
+		assert.Error(t, c.Replace(nil))
+		assert.Error(t, c.Replace(42))
+	})
+}
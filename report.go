@@ -0,0 +1,266 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"go.uber.org/dig/internal/digreflect"
+)
+
+// ErrorKind identifies the kind of failure described by a Report.
+type ErrorKind string
+
+// Kinds of failures described by a Report. These values are part of the
+// JSON encoding of reports and will not change.
+const (
+	// KindProvide is a failure to provide a constructor.
+	KindProvide ErrorKind = "provide"
+
+	// KindConstructorFailed is an error returned by a constructor.
+	KindConstructorFailed ErrorKind = "constructor-failed"
+
+	// KindConstructorPanicked is a panic in a constructor or invoked
+	// function, recovered with the RecoverPanics option.
+	KindConstructorPanicked ErrorKind = "constructor-panicked"
+
+	// KindConstructorTimedOut is a constructor which did not return within
+	// the duration given to the Timeout option.
+	KindConstructorTimedOut ErrorKind = "constructor-timed-out"
+
+	// KindCanceled is a function which was not called, or not waited for,
+	// because the context given to InvokeContext is done.
+	KindCanceled ErrorKind = "canceled"
+
+	// KindArgumentsFailed is a failure to build the arguments of a
+	// constructor or invoked function.
+	KindArgumentsFailed ErrorKind = "arguments-failed"
+
+	// KindMissingDependencies is a constructor or invoked function whose
+	// dependencies are not provided.
+	KindMissingDependencies ErrorKind = "missing-dependencies"
+
+	// KindParamFailed is a failure to build a value.
+	KindParamFailed ErrorKind = "param-failed"
+
+	// KindGroupFailed is a failure to build a value group.
+	KindGroupFailed ErrorKind = "group-failed"
+
+	// KindMissingTypes lists the values which are not provided.
+	KindMissingTypes ErrorKind = "missing-types"
+
+	// KindCycle is a dependency cycle.
+	KindCycle ErrorKind = "cycle"
+
+	// KindCycles holds the dependency cycles of a graph with more than one
+	// cycle, each as a KindCycle problem.
+	KindCycles ErrorKind = "cycles"
+
+	// KindGroupMismatch is a value requested by name but only provided to
+	// value groups, or the other way around.
+	KindGroupMismatch ErrorKind = "group-mismatch"
+
+	// KindVerify holds the problems found by Container.Verify.
+	KindVerify ErrorKind = "verify"
+
+	// KindWrapped adds context to its cause.
+	KindWrapped ErrorKind = "wrapped"
+
+	// KindError is an error that did not originate from dig, such as an
+	// error returned by a constructor.
+	KindError ErrorKind = "error"
+)
+
+// Report is a structured description of an error returned by dig, built by
+// ErrorReport. Each Report describes one error of the chain and links to the
+// next one with Cause.
+//
+// Reports are meant to be consumed by tools. Their JSON encoding is stable.
+type Report struct {
+	Kind ErrorKind `json:"kind"`
+
+	// Message of this error, without the messages of its causes.
+	Message string `json:"message"`
+
+	// Function in which the failure occurred, if any.
+	Func *ReportFunc `json:"func,omitempty"`
+
+	// Value that failed to build, if any.
+	Key *ReportKey `json:"key,omitempty"`
+
+	// Values that are not provided, for KindMissingTypes.
+	Missing []ReportMissingType `json:"missing,omitempty"`
+
+	// Dependency cycle, for KindCycle. The first and last entries are for
+	// the same value.
+	Cycle []ReportCycleEntry `json:"cycle,omitempty"`
+
+	// Independent problems, for KindVerify and KindCycles.
+	Problems []*Report `json:"problems,omitempty"`
+
+	// Next error in the chain, if any.
+	Cause *Report `json:"cause,omitempty"`
+}
+
+// ReportFunc identifies a function in a Report.
+type ReportFunc struct {
+	Package string `json:"package"`
+	Name    string `json:"name"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+}
+
+// ReportKey identifies a value in a Report.
+type ReportKey struct {
+	Type  string `json:"type"`
+	Name  string `json:"name,omitempty"`
+	Group string `json:"group,omitempty"`
+}
+
+// ReportMissingType is a value that is not provided, along with the values
+// that the user may have meant instead.
+type ReportMissingType struct {
+	Key         ReportKey   `json:"key"`
+	Suggestions []ReportKey `json:"suggestions,omitempty"`
+
+	// Explanations of why the value may be missing.
+	Hints []string `json:"hints,omitempty"`
+}
+
+// ReportCycleEntry is a value of a dependency cycle and the constructor
+// providing it.
+type ReportCycleEntry struct {
+	Key  ReportKey   `json:"key"`
+	Func *ReportFunc `json:"func"`
+}
+
+// ErrorReport returns a Report describing the given error and its chain of
+// causes, or nil if the error is nil.
+//
+//   if err := c.Invoke(run); err != nil {
+//     json.NewEncoder(os.Stderr).Encode(dig.ErrorReport(err))
+//   }
+//
+// Errors that did not originate from dig are reported with KindError. Their
+// chain is followed with errors.Unwrap.
+func ErrorReport(err error) *Report {
+	if err == nil {
+		return nil
+	}
+
+	r := &Report{Kind: KindError, Message: err.Error()}
+	switch e := err.(type) {
+	case wrappedError:
+		r.Kind = KindWrapped
+	case errProvide:
+		r.Kind = KindProvide
+		r.Func = reportFunc(e.Func)
+	case errConstructorFailed:
+		r.Kind = KindConstructorFailed
+		r.Func = reportFunc(e.Func)
+	case errConstructorPanicked:
+		r.Kind = KindConstructorPanicked
+		r.Func = reportFunc(e.Func)
+	case errConstructorTimedOut:
+		r.Kind = KindConstructorTimedOut
+		r.Func = reportFunc(e.Func)
+	case errCanceled:
+		r.Kind = KindCanceled
+		r.Func = reportFunc(e.Func)
+	case errArgumentsFailed:
+		r.Kind = KindArgumentsFailed
+		r.Func = reportFunc(e.Func)
+	case errMissingDependencies:
+		r.Kind = KindMissingDependencies
+		r.Func = reportFunc(e.Func)
+	case errParamSingleFailed:
+		r.Kind = KindParamFailed
+		r.Key = reportKey(e.Key)
+	case errParamGroupFailed:
+		r.Kind = KindGroupFailed
+		r.Key = reportKey(e.Key)
+	case errMissingTypes:
+		r.Kind = KindMissingTypes
+		for _, mt := range e {
+			m := ReportMissingType{Key: *reportKey(mt.Key), Hints: mt.hints}
+			for _, s := range mt.suggestions {
+				m.Suggestions = append(m.Suggestions, *reportKey(s))
+			}
+			r.Missing = append(r.Missing, m)
+		}
+	case errCycleDetected:
+		r.Kind = KindCycle
+		for _, entry := range e.Path {
+			r.Cycle = append(r.Cycle, ReportCycleEntry{
+				Key:  *reportKey(entry.Key),
+				Func: reportFunc(entry.Func),
+			})
+		}
+	case errCyclesDetected:
+		r.Kind = KindCycles
+		for _, cycle := range e {
+			r.Problems = append(r.Problems, ErrorReport(cycle))
+		}
+		r.Message = fmt.Sprintf("found %d cycles", len(e))
+		return r
+	case errGroupMismatch:
+		r.Kind = KindGroupMismatch
+		r.Func = reportFunc(e.Func)
+		r.Key = reportKey(e.Key)
+	case VerifyError:
+		r.Kind = KindVerify
+		for _, p := range e.Problems {
+			r.Problems = append(r.Problems, ErrorReport(p))
+		}
+		r.Message = e.summary()
+		return r
+	}
+
+	if c, ok := err.(causer); ok {
+		var b bytes.Buffer
+		c.writeMessage(&b, "%v")
+		r.Message = b.String()
+	}
+	r.Cause = ErrorReport(errors.Unwrap(err))
+	return r
+}
+
+func reportFunc(f *digreflect.Func) *ReportFunc {
+	if f == nil {
+		return nil
+	}
+	return &ReportFunc{
+		Package: f.Package,
+		Name:    f.Name,
+		File:    f.File,
+		Line:    f.Line,
+	}
+}
+
+func reportKey(k key) *ReportKey {
+	return &ReportKey{
+		Type:  k.t.String(),
+		Name:  k.name,
+		Group: k.group,
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/dig/internal/digreflect"
+)
+
+func TestErrorReport(t *testing.T) {
+	type type1 struct{}
+	type type2 struct{}
+
+	someFunc := &digreflect.Func{
+		Package: "foo",
+		Name:    "Bar",
+		File:    "foo/bar.go",
+		Line:    42,
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		assert.Nil(t, ErrorReport(nil))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		err := errArgumentsFailed{
+			Func: someFunc,
+			Reason: errParamSingleFailed{
+				Key: key{t: reflect.TypeOf(&type1{}), name: "foo"},
+				Reason: errMissingDependencies{
+					Func: someFunc,
+					Reason: errMissingTypes{{
+						Key:         key{t: reflect.TypeOf(type2{})},
+						suggestions: []key{{t: reflect.TypeOf(&type2{})}},
+					}},
+				},
+			},
+		}
+
+		b, jerr := json.MarshalIndent(ErrorReport(err), "", "  ")
+		require.NoError(t, jerr)
+		assert.Equal(t, `{
+  "kind": "arguments-failed",
+  "message": "could not build arguments for function \"foo\".Bar (foo/bar.go:42)",
+  "func": {
+    "package": "foo",
+    "name": "Bar",
+    "file": "foo/bar.go",
+    "line": 42
+  },
+  "cause": {
+    "kind": "param-failed",
+    "message": "failed to build *dig.type1[name=\"foo\"]",
+    "key": {
+      "type": "*dig.type1",
+      "name": "foo"
+    },
+    "cause": {
+      "kind": "missing-dependencies",
+      "message": "missing dependencies for function \"foo\".Bar (foo/bar.go:42)",
+      "func": {
+        "package": "foo",
+        "name": "Bar",
+        "file": "foo/bar.go",
+        "line": 42
+      },
+      "cause": {
+        "kind": "missing-types",
+        "message": "missing type: dig.type2 (did you mean *dig.type2?)",
+        "missing": [
+          {
+            "key": {
+              "type": "dig.type2"
+            },
+            "suggestions": [
+              {
+                "type": "*dig.type2"
+              }
+            ]
+          }
+        ]
+      }
+    }
+  }
+}`, string(b))
+	})
+
+	t.Run("constructor error", func(t *testing.T) {
+		sadness := errors.New("great sadness")
+
+		c := New()
+		require.NoError(t, c.Provide(func() (*type1, error) {
+			return nil, fmt.Errorf("wrapped: %w", sadness)
+		}, Group("ones")))
+		type params struct {
+			In
+
+			Ones []*type1 `group:"ones"`
+		}
+		err := c.Invoke(func(params) {})
+		require.Error(t, err)
+
+		var kinds []ErrorKind
+		r := ErrorReport(err)
+		for cur := r; cur != nil; cur = cur.Cause {
+			kinds = append(kinds, cur.Kind)
+		}
+		assert.Equal(t, []ErrorKind{
+			KindArgumentsFailed,
+			KindGroupFailed,
+			KindConstructorFailed,
+			KindError,
+			KindError,
+		}, kinds)
+
+		group := r.Cause
+		assert.Equal(t, &ReportKey{Type: "*dig.type1", Group: "ones"}, group.Key)
+		ctor := group.Cause
+		assert.Equal(t, "TestErrorReport.func3.1", ctor.Func.Name)
+		assert.Equal(t, "wrapped: great sadness", ctor.Cause.Message)
+		assert.Equal(t, "great sadness", ctor.Cause.Cause.Message)
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func(*type2) *type1 { return nil }))
+		err := c.Provide(func(*type1) *type2 { return nil })
+		require.Error(t, err)
+
+		r := ErrorReport(err)
+		assert.Equal(t, KindProvide, r.Kind)
+		assert.Equal(t, KindWrapped, r.Cause.Kind)
+		assert.Equal(t, "this function introduces a cycle", r.Cause.Message)
+
+		cycle := r.Cause.Cause
+		assert.Equal(t, KindCycle, cycle.Kind)
+		require.Len(t, cycle.Cycle, 3)
+		assert.Equal(t, ReportKey{Type: "*dig.type2"}, cycle.Cycle[0].Key)
+		assert.Equal(t, ReportKey{Type: "*dig.type1"}, cycle.Cycle[1].Key)
+		assert.Equal(t, ReportKey{Type: "*dig.type2"}, cycle.Cycle[2].Key)
+		assert.Equal(t, "TestErrorReport.func4.2", cycle.Cycle[0].Func.Name)
+	})
+
+	t.Run("verify", func(t *testing.T) {
+		type grouped struct {
+			In
+
+			Ones []*type1 `group:"one"`
+		}
+
+		c := New()
+		require.NoError(t, c.Provide(func() *type1 { return nil }, Name("one")))
+		err := c.Verify(func(grouped, *type2) {})
+		require.Error(t, err)
+
+		r := ErrorReport(err)
+		assert.Equal(t, KindVerify, r.Kind)
+		assert.Equal(t, "found 2 problems in the dependency graph", r.Message)
+		require.Len(t, r.Problems, 2)
+
+		assert.Equal(t, KindGroupMismatch, r.Problems[0].Kind)
+		assert.Equal(t, &ReportKey{Type: "*dig.type1", Group: "one"}, r.Problems[0].Key)
+		assert.Equal(t, KindMissingDependencies, r.Problems[1].Kind)
+		assert.Equal(t, KindMissingTypes, r.Problems[1].Cause.Kind)
+	})
+}
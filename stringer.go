@@ -23,26 +23,36 @@ package dig
 import (
 	"bytes"
 	"fmt"
+	"reflect"
+	"sort"
 	"strings"
+
+	"go.uber.org/dig/internal/digreflect"
 )
 
-// String representation of the entire Container
+// String representation of the entire Container. Constructors and values are
+// sorted by key, and constructors of the same key by location, so that the
+// output is the same every time.
 func (c *Container) String() string {
 	b := &bytes.Buffer{}
 	fmt.Fprintln(b, "nodes: {")
-	for k, vs := range c.providers {
-		for _, v := range vs {
+	for _, k := range providerKeys(c.providers) {
+		nodes := append([]*node(nil), c.providers[k]...)
+		sort.SliceStable(nodes, func(i, j int) bool {
+			return compareLocations(nodes[i].location, nodes[j].location) < 0
+		})
+		for _, v := range nodes {
 			fmt.Fprintln(b, "\t", k, "->", v)
 		}
 	}
 	fmt.Fprintln(b, "}")
 
 	fmt.Fprintln(b, "values: {")
-	for k, v := range c.values {
-		fmt.Fprintln(b, "\t", k, "=>", v)
+	for _, k := range valueKeys(c.values) {
+		fmt.Fprintln(b, "\t", k, "=>", c.values[k])
 	}
-	for k, vs := range c.groups {
-		for _, v := range vs {
+	for _, k := range groupKeys(c.groups) {
+		for _, v := range c.groups[k] {
 			fmt.Fprintln(b, "\t", k, "=>", v)
 		}
 	}
@@ -51,6 +61,76 @@ func (c *Container) String() string {
 	return b.String()
 }
 
+// providerKeys returns the keys of the providers of a container in the
+// order of sortKeys.
+func providerKeys(providers map[key][]*node) []key {
+	keys := make([]key, 0, len(providers))
+	for k := range providers {
+		keys = append(keys, k)
+	}
+	return sortKeys(keys)
+}
+
+// valueKeys returns the keys of the values of a container in the order of
+// sortKeys.
+func valueKeys(values map[key]reflect.Value) []key {
+	keys := make([]key, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	return sortKeys(keys)
+}
+
+// groupKeys returns the keys of the value groups of a container in the
+// order of sortKeys.
+func groupKeys(groups map[key][]reflect.Value) []key {
+	keys := make([]key, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	return sortKeys(keys)
+}
+
+// sortKeys sorts keys by their string representation and then by the
+// package path of their type, which tells apart types of the same name from
+// different packages.
+func sortKeys(keys []key) []key {
+	sort.SliceStable(keys, func(i, j int) bool {
+		if a, b := keys[i].String(), keys[j].String(); a != b {
+			return a < b
+		}
+		return typePkgPath(keys[i].t) < typePkgPath(keys[j].t)
+	})
+	return keys
+}
+
+// typePkgPath returns the package path of t, or of the type it points to or
+// holds for pointers, slices, arrays, maps and channels.
+func typePkgPath(t reflect.Type) string {
+	for {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map, reflect.Chan:
+			t = t.Elem()
+		default:
+			return t.PkgPath()
+		}
+	}
+}
+
+// compareLocations orders functions by package, name, file and line.
+func compareLocations(a, b *digreflect.Func) int {
+	switch {
+	case a.Package != b.Package:
+		return strings.Compare(a.Package, b.Package)
+	case a.Name != b.Name:
+		return strings.Compare(a.Name, b.Name)
+	case a.File != b.File:
+		return strings.Compare(a.File, b.File)
+	default:
+		return a.Line - b.Line
+	}
+}
+
 func (n *node) String() string {
 	return fmt.Sprintf("deps: %v, ctor: %v", n.paramList, n.ctype)
 }
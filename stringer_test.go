@@ -21,8 +21,11 @@
 package dig
 
 import (
+	htmltemplate "html/template"
 	"math/rand"
+	"reflect"
 	"testing"
+	"text/template"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -83,6 +86,9 @@ func TestStringer(t *testing.T) {
 	}))
 
 	s := c.String()
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, s, c.String(), "String must be deterministic")
+	}
 
 	// All nodes
 	assert.Contains(t, s, `dig.A[name="foo"] -> deps: []`)
@@ -106,3 +112,14 @@ func TestStringer(t *testing.T) {
 	assert.Contains(t, s, `string[group="baz"] => bar`)
 	assert.Contains(t, s, `string[group="baz"] => baz`)
 }
+
+func TestSortKeys(t *testing.T) {
+	text := key{t: reflect.TypeOf(&template.Template{})}
+	html := key{t: reflect.TypeOf(&htmltemplate.Template{})}
+	require.Equal(t, text.String(), html.String())
+
+	for _, keys := range [][]key{{text, html}, {html, text}} {
+		assert.Equal(t, []key{html, text}, sortKeys(keys),
+			"types of the same name must be sorted by package path")
+	}
+}
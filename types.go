@@ -22,7 +22,10 @@ package dig
 
 import (
 	"container/list"
+	"fmt"
 	"reflect"
+
+	"go.uber.org/dig/internal/digreflect"
 )
 
 var (
@@ -157,3 +160,41 @@ func embedsType(i interface{}, e reflect.Type) bool {
 	// map[reflect.Type]struct{}.
 	return false
 }
+
+// KeyInfo identifies a value in a container.
+type KeyInfo struct {
+	Type reflect.Type
+
+	// Only one of Name or Group is set.
+	Name  string
+	Group string
+}
+
+func (k KeyInfo) String() string {
+	return key{t: k.Type, name: k.Name, group: k.Group}.String()
+}
+
+func (k key) info() KeyInfo {
+	return KeyInfo{Type: k.t, Name: k.name, Group: k.group}
+}
+
+// Location is where a function passed to a container is defined.
+type Location struct {
+	Package string
+	Name    string
+	File    string
+	Line    int
+}
+
+func (l Location) String() string {
+	return fmt.Sprintf("%q.%v (%v:%v)", l.Package, l.Name, l.File, l.Line)
+}
+
+func newLocation(f *digreflect.Func) Location {
+	return Location{
+		Package: f.Package,
+		Name:    f.Name,
+		File:    f.File,
+		Line:    f.Line,
+	}
+}
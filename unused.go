@@ -0,0 +1,146 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+)
+
+// UnusedReport lists the parts of a container that the functions passed to
+// Container.Unused do not need.
+type UnusedReport struct {
+	// Providers are the constructors, in the order they were provided,
+	// that are never called to build the dependencies of the functions.
+	Providers []ProviderInfo
+
+	// Values are the named values and aliases, sorted by key, that are
+	// built by constructors which are needed but that nothing requests by
+	// that name.
+	Values []KeyInfo
+}
+
+// Empty reports whether nothing is unused.
+func (r UnusedReport) Empty() bool {
+	return len(r.Providers) == 0 && len(r.Values) == 0
+}
+
+// Unused reports the constructors and named values of the container that
+// are not needed, directly or transitively, by any of the given functions.
+// The functions are typically the functions passed to Invoke by the
+// application. Values requested through inject tags and value groups are
+// taken into account. No constructors are called.
+func (c *Container) Unused(roots ...interface{}) (UnusedReport, error) {
+	pls := make([]paramList, len(roots))
+	for i, root := range roots {
+		ftype := reflect.TypeOf(root)
+		if ftype == nil {
+			return UnusedReport{}, errors.New("can't use an untyped nil as a root")
+		}
+		if ftype.Kind() != reflect.Func {
+			return UnusedReport{}, errf("root must be a function, got %v (type %v)", root, ftype)
+		}
+		pl, err := newParamList(ftype)
+		if err != nil {
+			return UnusedReport{}, err
+		}
+		pls[i] = pl
+	}
+
+	used, requested := c.reachableNodes(pls)
+
+	var report UnusedReport
+	named := make(map[key]struct{})
+	for _, n := range c.nodes {
+		if _, ok := used[n]; !ok {
+			report.Providers = append(report.Providers, c.providerInfo(n))
+			continue
+		}
+		for _, k := range resultKeys(n.resultList) {
+			if k.name != "" {
+				named[k] = struct{}{}
+			}
+		}
+	}
+	for a, k := range c.aliases {
+		if _, ok := named[k]; ok {
+			named[a] = struct{}{}
+		}
+	}
+
+	var unrequested []key
+	for k := range named {
+		if _, ok := requested[k]; !ok {
+			unrequested = append(unrequested, k)
+		}
+	}
+	sort.Slice(unrequested, func(i, j int) bool {
+		return unrequested[i].String() < unrequested[j].String()
+	})
+	for _, k := range unrequested {
+		report.Values = append(report.Values, k.info())
+	}
+	return report, nil
+}
+
+// reachableNodes returns the nodes whose constructors may be called to build
+// the given params, including params requested through inject tags, and the
+// keys requested along the way before aliases are resolved.
+func (c *Container) reachableNodes(params []paramList) (map[*node]struct{}, map[key]struct{}) {
+	used := make(map[*node]struct{})
+	requested := make(map[key]struct{})
+	visited := make(map[key]struct{})
+
+	var visit func(param)
+	visit = func(p param) {
+		walkParam(p, paramVisitorFunc(func(p param) bool {
+			var k key
+			switch p := p.(type) {
+			case paramSingle:
+				requested[key{name: p.Name, t: p.Type}] = struct{}{}
+				k = c.resolveAlias(key{name: p.Name, t: p.Type})
+			case paramGroupedSlice:
+				k = key{group: p.Group, t: p.Type.Elem()}
+			default:
+				return true
+			}
+
+			if _, ok := visited[k]; ok {
+				return false
+			}
+			visited[k] = struct{}{}
+
+			for _, n := range c.providers[k] {
+				if _, ok := used[n]; !ok {
+					used[n] = struct{}{}
+					visit(n.paramList)
+				}
+			}
+			return true
+		}))
+	}
+
+	for _, pl := range params {
+		visit(pl)
+	}
+	return used, requested
+}
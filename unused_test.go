@@ -0,0 +1,110 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnused(t *testing.T) {
+	type A struct{}
+	type B struct{}
+	type C struct{}
+	type injected struct {
+		B *B `inject:""`
+	}
+	type out struct {
+		Out
+
+		A     *A `name:"primary"`
+		Spare *A `name:"spare"`
+	}
+
+	c := New()
+	require.NoError(t, c.Provide(func() out { return out{} }))
+	require.NoError(t, c.Provide(func() *B { return &B{} }))
+	require.NoError(t, c.Provide(func() *injected { return &injected{} }))
+	require.NoError(t, c.Provide(func() string { return "s" }, Group("s")))
+	require.NoError(t, c.Provide(func() *C { return &C{} }))
+	require.NoError(t, c.Alias("main", "primary", new(*A)))
+
+	type param struct {
+		In
+
+		A        *A `name:"main"`
+		Injected *injected
+		Strings  []string `group:"s"`
+	}
+
+	t.Run("reports unused providers and values", func(t *testing.T) {
+		report, err := c.Unused(func(param) {})
+		require.NoError(t, err)
+		assert.False(t, report.Empty())
+
+		require.Len(t, report.Providers, 1)
+		assert.Equal(t, []KeyInfo{{Type: reflect.TypeOf(&C{})}}, report.Providers[0].Results)
+
+		var values []string
+		for _, k := range report.Values {
+			values = append(values, k.String())
+		}
+		assert.Equal(t, []string{`*dig.A[name="primary"]`, `*dig.A[name="spare"]`}, values)
+	})
+
+	t.Run("nothing unused", func(t *testing.T) {
+		type named struct {
+			In
+
+			A *A `name:"primary"`
+			S *A `name:"spare"`
+		}
+		report, err := c.Unused(func(param, *C, named) {})
+		require.NoError(t, err)
+		assert.True(t, report.Empty(), "unexpected report: %+v", report)
+	})
+
+	t.Run("no roots", func(t *testing.T) {
+		report, err := c.Unused()
+		require.NoError(t, err)
+		assert.Len(t, report.Providers, 5)
+		assert.Empty(t, report.Values)
+	})
+
+	t.Run("invalid roots", func(t *testing.T) {
+		_, err := c.Unused(nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "untyped nil")
+
+		_, err = c.Unused(42)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "root must be a function")
+	})
+
+	t.Run("does not call constructors", func(t *testing.T) {
+		for _, n := range c.nodes {
+			assert.False(t, n.called)
+		}
+	})
+}
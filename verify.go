@@ -0,0 +1,334 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"go.uber.org/dig/internal/digreflect"
+	"go.uber.org/dig/internal/dot"
+)
+
+// Verify checks that the given functions could be invoked on the container
+// without calling any constructors or the functions themselves. If no
+// functions are given, every constructor in the container is checked
+// instead.
+//
+//   if err := c.Verify(app.Run); err != nil {
+//     log.Fatalf("%+v", err)
+//   }
+//
+// Where Invoke stops at the first missing dependency, Verify walks all
+// dependencies of the functions transitively, including fields tagged with
+// `inject` and values provided by PassiveProvide fallbacks, and reports every
+// problem found at once. The returned error is a VerifyError. It lists
+// missing types, dependency cycles, and values which are requested by name
+// but only provided to value groups or the other way around.
+func (c *Container) Verify(roots ...interface{}) error {
+	v := verifier{
+		c:        c,
+		checked:  make(map[*node]bool),
+		passives: make(map[key]struct{}),
+	}
+
+	if len(roots) == 0 {
+		for _, n := range c.nodes {
+			v.checkNode(n, false /* optional */)
+		}
+	}
+
+	for _, root := range roots {
+		ftype := reflect.TypeOf(root)
+		if ftype == nil {
+			return errors.New("can't verify an untyped nil")
+		}
+		if ftype.Kind() != reflect.Func {
+			return errf("can't verify non-function %v (type %v)", root, ftype)
+		}
+
+		pl, err := newParamList(ftype)
+		if err != nil {
+			return err
+		}
+		v.checkParams(digreflect.InspectFunc(root), pl, true /* passive */)
+	}
+
+	v.checkCycles()
+
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return VerifyError{Problems: v.problems}
+}
+
+// VerifyError is returned by Container.Verify with every problem found in the
+// dependency graph.
+type VerifyError struct {
+	// Problems holds an error for each problem, in a deterministic order.
+	// These are the same errors that Invoke would fail with for the problem,
+	// so functions like IsCycleDetected may be used on them.
+	Problems []error
+}
+
+var _ errVisualizer = VerifyError{}
+
+func (e VerifyError) Error() string { return fmt.Sprint(e) }
+
+// Format implements fmt.Formatter. With %+v, every problem is written on
+// its own lines.
+func (e VerifyError) Format(w fmt.State, c rune) {
+	multiline := w.Flag('+') && c == 'v'
+
+	io.WriteString(w, e.summary())
+	io.WriteString(w, ":")
+
+	for i, p := range e.Problems {
+		if !multiline {
+			if i > 0 {
+				io.WriteString(w, ";")
+			}
+			fmt.Fprintf(w, " %v", p)
+			continue
+		}
+
+		msg := fmt.Sprintf("%+v", p)
+		io.WriteString(w, "\n\t- ")
+		io.WriteString(w, strings.Replace(msg, "\n", "\n\t  ", -1))
+	}
+}
+
+func (e VerifyError) summary() string {
+	if len(e.Problems) == 1 {
+		return "found 1 problem in the dependency graph"
+	}
+	return fmt.Sprintf("found %d problems in the dependency graph", len(e.Problems))
+}
+
+// Is reports whether any of the problems matches target, for errors.Is.
+func (e VerifyError) Is(target error) bool {
+	for _, p := range e.Problems {
+		if errors.Is(p, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first problem that matches target, for errors.As.
+func (e VerifyError) As(target interface{}) bool {
+	for _, p := range e.Problems {
+		if errors.As(p, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e VerifyError) updateGraph(g *dot.Graph) {
+	for _, p := range e.Problems {
+		g.StartFailure()
+		visualizeErr(g, p)
+	}
+}
+
+// errGroupMismatch is a problem found by Verify where a value is requested
+// by name but only provided to value groups, or where an empty value group
+// is requested but a value with the group's name is provided.
+type errGroupMismatch struct {
+	Func     *digreflect.Func // function requesting the value
+	Key      key              // requested value
+	Provided []key            // what the container provides instead
+}
+
+var _ errVisualizer = errGroupMismatch{}
+
+func (e errGroupMismatch) Error() string {
+	provided := make([]string, len(e.Provided))
+	for i, k := range e.Provided {
+		provided[i] = k.String()
+	}
+	return fmt.Sprintf("%v requested by %v is not provided but %v is, "+
+		"check the name and group tags", e.Key, e.Func, strings.Join(provided, ", "))
+}
+
+func (e errGroupMismatch) updateGraph(g *dot.Graph) {
+	g.AddMissingNodes([]*dot.Result{{
+		Node: &dot.Node{
+			Name:  e.Key.name,
+			Group: e.Key.group,
+			Type:  e.Key.t,
+		},
+	}})
+}
+
+type verifier struct {
+	c *Container
+
+	// Nodes that were checked, and whether they were checked as required
+	// dependencies. Nodes that are only reached through optional parameters
+	// may have missing dependencies.
+	checked map[*node]bool
+
+	// PassiveProvide fallbacks that were checked.
+	passives map[key]struct{}
+
+	problems []error
+}
+
+func (v *verifier) checkNode(n *node, optional bool) {
+	if required, ok := v.checked[n]; ok && (required || optional) {
+		return
+	}
+	v.checked[n] = !optional
+
+	missing := v.checkParams(n.location, n.paramList, false /* passive */)
+	if len(missing) > 0 && !optional {
+		v.problems = append(v.problems, errMissingDependencies{
+			Func:   n.location,
+			Reason: missing,
+		})
+	}
+}
+
+// checkParams checks the dependencies of the given function and returns the
+// ones that are missing. passive is set for the functions given to Verify:
+// like in Invoke, only their parameters fall back to PassiveProvide, and
+// their missing dependencies are recorded as a problem right away.
+func (v *verifier) checkParams(f *digreflect.Func, p param, passive bool) errMissingTypes {
+	var missing errMissingTypes
+	walkParam(p, paramVisitorFunc(func(p param) bool {
+		switch p := p.(type) {
+		case paramSingle:
+			k := v.c.resolveAlias(key{name: p.Name, t: p.Type})
+			providers := v.c.providers[k]
+			if len(providers) > 0 {
+				for _, n := range providers {
+					v.checkNode(n, p.Optional)
+				}
+				return true
+			}
+
+			if pp, ok := v.c.passives[key{t: p.Type}]; ok && passive {
+				v.checkPassive(key{t: p.Type}, pp)
+				return true
+			}
+			if p.Optional {
+				return true
+			}
+
+			if groups := v.groupsOf(p.Type); len(groups) > 0 {
+				v.problems = append(v.problems, errGroupMismatch{
+					Func:     f,
+					Key:      k,
+					Provided: groups,
+				})
+				return true
+			}
+			missing = append(missing, newErrMissingTypes(v.c, k)...)
+
+		case paramGroupedSlice:
+			k := key{group: p.Group, t: p.Type.Elem()}
+			providers := v.c.providers[k]
+			for _, n := range providers {
+				v.checkNode(n, false /* optional */)
+			}
+
+			// Empty value groups are valid, unless the group was probably
+			// meant to be a named value.
+			named := key{name: p.Group, t: k.t}
+			if len(providers) == 0 && len(v.c.providers[v.c.resolveAlias(named)]) > 0 {
+				v.problems = append(v.problems, errGroupMismatch{
+					Func:     f,
+					Key:      k,
+					Provided: []key{named},
+				})
+			}
+		}
+		return true
+	}))
+
+	if len(missing) > 0 && passive {
+		v.problems = append(v.problems, errMissingDependencies{
+			Func:   f,
+			Reason: missing,
+		})
+	}
+	return missing
+}
+
+// checkPassive checks the dependencies of a PassiveProvide constructor,
+// except for the name which is supplied by the container.
+func (v *verifier) checkPassive(k key, pp passiveProvider) {
+	if _, ok := v.passives[k]; ok {
+		return
+	}
+	v.passives[k] = struct{}{}
+
+	pl, err := newParamList(reflect.TypeOf(pp.ctor))
+	if err != nil {
+		v.problems = append(v.problems, err)
+		return
+	}
+	params := append([]param(nil), pl.Params[:pp.nameParamIndex]...)
+	pl.Params = append(params, pl.Params[pp.nameParamIndex+1:]...)
+
+	f := digreflect.InspectFunc(pp.ctor)
+	if missing := v.checkParams(f, pl, false /* passive */); len(missing) > 0 {
+		v.problems = append(v.problems, errMissingDependencies{
+			Func:   f,
+			Reason: missing,
+		})
+	}
+}
+
+// groupsOf returns the value groups that values of the given type are
+// provided to, in a deterministic order.
+func (v *verifier) groupsOf(t reflect.Type) []key {
+	var groups []key
+	for k := range v.c.providers {
+		if k.t == t && k.group != "" {
+			groups = append(groups, k)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].group < groups[j].group
+	})
+	return groups
+}
+
+// checkCycles records every cycle that the checked nodes are part of.
+func (v *verifier) checkCycles() {
+	var providers []provider
+	for _, n := range v.c.nodes {
+		if _, ok := v.checked[n]; ok {
+			providers = append(providers, n)
+		}
+	}
+
+	for _, cycle := range findCycles(v.c, providers) {
+		v.problems = append(v.problems, errf("cycle detected in dependency graph", cycle))
+	}
+}
@@ -0,0 +1,268 @@
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package dig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	type (
+		Config  struct{}
+		Logger  struct{}
+		Handler struct{}
+		Server  struct{}
+	)
+
+	verifyError := func(t *testing.T, err error) VerifyError {
+		require.Error(t, err)
+		ve, ok := err.(VerifyError)
+		require.True(t, ok, "expected a VerifyError, got %T", err)
+		return ve
+	}
+
+	t.Run("no problems", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func() *Config {
+			t.Fatal("constructor must not be called")
+			return nil
+		}))
+		require.NoError(t, c.Provide(func(*Config) *Logger {
+			t.Fatal("constructor must not be called")
+			return nil
+		}))
+
+		assert.NoError(t, c.Verify(func(*Logger) {
+			t.Fatal("function must not be called")
+		}))
+		assert.NoError(t, c.Verify())
+	})
+
+	t.Run("all missing types are reported", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func(*Config) *Logger { return &Logger{} }))
+		require.NoError(t, c.Provide(func(*Logger, *Handler) *Server { return &Server{} }))
+
+		ve := verifyError(t, c.Verify(func(*Server, *Config) {}))
+		require.Len(t, ve.Problems, 3)
+
+		// Dependencies are reported before the functions that need them.
+		assert.Contains(t, ve.Problems[0].Error(), "missing dependencies for function")
+		assert.Contains(t, ve.Problems[0].Error(), "TestVerify.func3.1")
+		assert.Contains(t, ve.Problems[0].Error(), "missing type: *dig.Config")
+
+		assert.Contains(t, ve.Problems[1].Error(), "TestVerify.func3.2")
+		assert.Contains(t, ve.Problems[1].Error(), "missing type: *dig.Handler")
+
+		assert.Contains(t, ve.Problems[2].Error(), "TestVerify.func3.3")
+		assert.Contains(t, ve.Problems[2].Error(), "missing type: *dig.Config")
+
+		assert.True(t, IsMissingType(ve))
+		var mte MissingTypeError
+		require.True(t, errors.As(ve, &mte))
+		assert.Equal(t, []KeyInfo{{Type: reflect.TypeOf(&Config{})}}, mte.Keys)
+
+		assert.Contains(t, ve.Error(), "found 3 problems in the dependency graph: ")
+		assert.Contains(t, fmt.Sprintf("%+v", ve), "found 3 problems in the dependency graph:\n\t- missing dependencies")
+	})
+
+	t.Run("without roots every constructor is verified", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func(*Config) *Logger { return &Logger{} }))
+		require.NoError(t, c.Provide(func(*Handler) *Server { return &Server{} }))
+
+		ve := verifyError(t, c.Verify())
+		require.Len(t, ve.Problems, 2)
+		assert.Contains(t, ve.Problems[0].Error(), "missing type: *dig.Config")
+		assert.Contains(t, ve.Problems[1].Error(), "missing type: *dig.Handler")
+
+		ve = verifyError(t, c.Verify(func(*Logger) {}))
+		assert.Len(t, ve.Problems, 1, "only reachable constructors are verified")
+	})
+
+	t.Run("optional dependencies", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func(*Config) *Logger { return &Logger{} }))
+
+		type params struct {
+			In
+
+			Logger  *Logger  `optional:"true"`
+			Handler *Handler `optional:"true"`
+		}
+		assert.NoError(t, c.Verify(func(params) {}))
+
+		ve := verifyError(t, c.Verify(func(params, *Logger) {}))
+		require.Len(t, ve.Problems, 1)
+		assert.Contains(t, ve.Problems[0].Error(), "missing type: *dig.Config")
+	})
+
+	t.Run("inject fields", func(t *testing.T) {
+		type Bean struct {
+			Logger *Logger `inject:"logger"`
+		}
+
+		c := New()
+		require.NoError(t, c.Provide(func() *Bean { return &Bean{} }))
+
+		ve := verifyError(t, c.Verify(func(*Bean) {}))
+		require.Len(t, ve.Problems, 1)
+		assert.Contains(t, ve.Problems[0].Error(), `missing type: *dig.Logger[name="logger"]`)
+
+		require.NoError(t, c.Provide(func() *Logger { return &Logger{} }, Name("logger")))
+		assert.NoError(t, c.Verify(func(*Bean) {}))
+	})
+
+	t.Run("passive fallbacks", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.PassiveProvide(func(name string, _ *Config) (*DB, error) {
+			return &DB{Name: name}, nil
+		}))
+
+		type params struct {
+			In
+
+			DB *DB `name:"main"`
+		}
+		ve := verifyError(t, c.Verify(func(params) {}))
+		require.Len(t, ve.Problems, 1)
+		assert.Contains(t, ve.Problems[0].Error(), "TestVerify.func7.1")
+		assert.Contains(t, ve.Problems[0].Error(), "missing type: *dig.Config")
+
+		require.NoError(t, c.Provide(func() *Config { return &Config{} }))
+		assert.NoError(t, c.Verify(func(params) {}))
+
+		// Like Invoke, constructors don't fall back to passive providers.
+		require.NoError(t, c.Provide(func(p params) *Server { return &Server{} }))
+		ve = verifyError(t, c.Verify(func(*Server) {}))
+		require.Len(t, ve.Problems, 1)
+		assert.Contains(t, ve.Problems[0].Error(), `missing type: *dig.DB[name="main"]`)
+	})
+
+	t.Run("group and name mismatches", func(t *testing.T) {
+		type handlers struct {
+			Out
+
+			Handler *Handler `group:"handlers"`
+		}
+
+		c := New()
+		require.NoError(t, c.Provide(func() handlers { return handlers{} }))
+		require.NoError(t, c.Provide(func() *Config { return &Config{} }, Name("configs")))
+
+		type named struct {
+			In
+
+			Handler *Handler `name:"handlers"`
+		}
+		ve := verifyError(t, c.Verify(func(named) {}))
+		require.Len(t, ve.Problems, 1)
+		assert.Contains(t, ve.Problems[0].Error(),
+			`*dig.Handler[name="handlers"] requested by`)
+		assert.Contains(t, ve.Problems[0].Error(),
+			`is not provided but *dig.Handler[group="handlers"] is, check the name and group tags`)
+
+		type grouped struct {
+			In
+
+			Configs  []*Config  `group:"configs"`
+			Handlers []*Handler `group:"handlers"`
+			Servers  []*Server  `group:"servers"`
+		}
+		ve = verifyError(t, c.Verify(func(grouped) {}))
+		require.Len(t, ve.Problems, 1, "empty groups are valid")
+		assert.Contains(t, ve.Problems[0].Error(),
+			`*dig.Config[group="configs"] requested by`)
+		assert.Contains(t, ve.Problems[0].Error(), `but *dig.Config[name="configs"] is`)
+	})
+
+	t.Run("all cycles are reported", func(t *testing.T) {
+		type (
+			A struct{}
+			B struct{}
+			C struct{}
+			D struct{}
+		)
+
+		c := New(DeferAcyclicVerification())
+		require.NoError(t, c.Provide(func(*B) *A { return &A{} }))
+		require.NoError(t, c.Provide(func(*A) *B { return &B{} }))
+		require.NoError(t, c.Provide(func(*D) *C { return &C{} }))
+		require.NoError(t, c.Provide(func(*C) *D { return &D{} }))
+
+		ve := verifyError(t, c.Verify(func(*A, *C) {}))
+		require.Len(t, ve.Problems, 2)
+		for _, p := range ve.Problems {
+			assert.True(t, IsCycleDetected(p), "expected a cycle, got %v", p)
+		}
+		assert.Contains(t, ve.Problems[0].Error(), "*dig.A provided by")
+		assert.Contains(t, ve.Problems[1].Error(), "*dig.C provided by")
+	})
+
+	t.Run("visualize", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.Provide(func(*Config) *Logger { return &Logger{} }))
+		require.NoError(t, c.Provide(func(*Handler) *Server { return &Server{} }))
+
+		err := c.Verify()
+		require.Error(t, err)
+
+		var b bytes.Buffer
+		require.NoError(t, Visualize(c, &b, VisualizeError(err)))
+		assert.Contains(t, b.String(), `"*dig.Config" [color=red];`)
+		assert.Contains(t, b.String(), `"*dig.Handler" [color=red];`)
+	})
+
+	t.Run("invalid roots", func(t *testing.T) {
+		c := New()
+		assert.EqualError(t, c.Verify(nil), "can't verify an untyped nil")
+		assert.EqualError(t, c.Verify(42), "can't verify non-function 42 (type int)")
+		assert.Error(t, c.Verify(func(struct {
+			In
+
+			x int
+		}) {
+		}))
+	})
+
+	t.Run("passive providers are verified once", func(t *testing.T) {
+		c := New()
+		require.NoError(t, c.PassiveProvide(func(name string, _ *Config) (*DB, error) {
+			return &DB{Name: name}, nil
+		}))
+
+		type params struct {
+			In
+
+			A *DB `name:"a"`
+			B *DB `name:"b"`
+		}
+		ve := verifyError(t, c.Verify(func(params) {}))
+		assert.Len(t, ve.Problems, 1)
+	})
+}
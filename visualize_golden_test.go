@@ -37,7 +37,17 @@ func VerifyVisualization(t *testing.T, testname string, c *Container, opts ...Vi
 	var b bytes.Buffer
 	require.NoError(t, Visualize(c, &b, opts...))
 
-	dotFile := filepath.Join("testdata", testname+".dot")
+	var options visualizeOptions
+	for _, o := range opts {
+		o.applyVisualizeOption(&options)
+	}
+	ext := map[GraphFormat]string{
+		FormatDOT:     ".dot",
+		FormatMermaid: ".mmd",
+		FormatGraphML: ".graphml",
+		FormatJSON:    ".json",
+	}[options.Format]
+	dotFile := filepath.Join("testdata", testname+ext)
 
 	if *generate {
 		err := ioutil.WriteFile(dotFile, b.Bytes(), 0644)
@@ -53,3 +63,15 @@ func VerifyVisualization(t *testing.T, testname string, c *Container, opts ...Vi
 	assert.Equal(t, want, got,
 		"Output did not match. Make sure you updated the testdata by running 'go test -generate'")
 }
+
+// pinLocations replaces the file and line of the constructors in the
+// container, which are part of formats other than DOT, to keep the golden
+// files independent of the checkout and of changes to the tests.
+func pinLocations(c *Container) {
+	for _, n := range c.nodes {
+		loc := *n.location
+		loc.File = filepath.Base(loc.File)
+		loc.Line = 1
+		n.location = &loc
+	}
+}